@@ -0,0 +1,19 @@
+package comicshelf
+
+import (
+	"context"
+	"time"
+)
+
+type Character struct {
+	Id          int    `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Urls        []Url  `json:"urls"`
+	Thumbnail   string `json:"thumbnail"`
+}
+
+type CharacterService interface {
+	GetCharacter(ctx context.Context, id int) (Character, error)
+	GetComicsWithCharacter(ctx context.Context, id int, from, to time.Time) ([]Comic, error)
+}
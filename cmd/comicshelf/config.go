@@ -8,8 +8,13 @@ import (
 	"log/slog"
 	"os"
 
+	"github.com/jakedegiovanni/comicshelf/internal/catalogue"
 	"github.com/jakedegiovanni/comicshelf/internal/filedb"
+	"github.com/jakedegiovanni/comicshelf/internal/lifecycle"
+	"github.com/jakedegiovanni/comicshelf/internal/logging"
 	"github.com/jakedegiovanni/comicshelf/internal/server"
+	"github.com/jakedegiovanni/comicshelf/internal/tracing"
+	"github.com/jakedegiovanni/comicshelf/internal/unlimited"
 	"github.com/jakedegiovanni/comicshelf/marvel"
 )
 
@@ -21,16 +26,22 @@ type ctxKey struct{ key string }
 var cfgCtxKey = &ctxKey{"cfg"}
 
 type config struct {
-	File   string        `mapstructure:"file"`
-	Marvel marvel.Config `mapstructure:"marvel"`
-	Server server.Config `mapstructure:"server"`
-	FileDB filedb.Config `mapstructure:"filedb"`
-	Logger LoggingConfig `mapstructure:"logger"`
+	File      string           `mapstructure:"file"`
+	Marvel    marvel.Config    `mapstructure:"marvel"`
+	Server    server.Config    `mapstructure:"server"`
+	FileDB    filedb.Config    `mapstructure:"filedb"`
+	Catalogue catalogue.Config `mapstructure:"catalogue"`
+	Unlimited unlimited.Config `mapstructure:"unlimited"`
+	Tracing   tracing.Config   `mapstructure:"tracing"`
+	Lifecycle lifecycle.Config `mapstructure:"lifecycle"`
+	Logger    LoggingConfig    `mapstructure:"logger"`
 }
 
 type LoggingConfig struct {
 	Level    slog.Level `mapstructure:"level"`
 	Disabled bool       `mapstructure:"disabled"`
+	// Format is either text or json.
+	Format string `mapstructure:"format"`
 }
 
 func (l LoggingConfig) Writer() io.Writer {
@@ -43,13 +54,17 @@ func (l LoggingConfig) Writer() io.Writer {
 
 func (l LoggingConfig) Slog() *slog.Logger {
 	w := l.Writer()
-
-	logger := slog.New(slog.NewTextHandler(w, &slog.HandlerOptions{
+	opts := &slog.HandlerOptions{
 		AddSource: true,
 		Level:     l.Level,
-	}))
+	}
+
+	var handler slog.Handler = slog.NewTextHandler(w, opts)
+	if l.Format == "json" {
+		handler = slog.NewJSONHandler(w, opts)
+	}
 
-	return logger
+	return slog.New(logging.NewHandler(handler))
 }
 
 func getConfigFromCtx(ctx context.Context) (*config, error) {
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/jakedegiovanni/comicshelf/internal/filedb"
+	"github.com/jakedegiovanni/comicshelf/internal/importer"
+	"github.com/jakedegiovanni/comicshelf/internal/search"
+	"github.com/jakedegiovanni/comicshelf/marvel"
+	"github.com/spf13/cobra"
+)
+
+func importCmd() *cobra.Command {
+	var format string
+	var dryRun bool
+
+	cmd := &cobra.Command{
+		Use:   "import [file]",
+		Short: "import follows and read issues from a csv or comic tracker export, refused while the server is running",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) (err error) {
+			cfg, err := getConfigFromCtx(cmd.Context())
+			if err != nil {
+				return err
+			}
+
+			f, err := os.Open(args[0])
+			if err != nil {
+				return fmt.Errorf("could not open import file: %w", err)
+			}
+			defer f.Close()
+
+			rows, err := importer.Parse(importer.Format(format), f)
+			if err != nil {
+				return err
+			}
+
+			// a dry run reads the store without locking or saving it, so it can run beside the server
+			dbCfg := cfg.FileDB
+			dbCfg.ReadOnly = dryRun
+
+			userSvc, err := filedb.New(&dbCfg)
+			if err != nil {
+				return err
+			}
+			defer func() {
+				err = errors.Join(err, userSvc.Shutdown(context.Background()))
+			}()
+
+			marvelSvc := marvel.New(&cfg.Marvel)
+
+			report, err := importer.New(marvelSvc, importer.ScorerFunc(search.Similarity), userSvc, 0, dryRun).Import(cmd.Context(), rows) // using default user id until auth actually implemented
+			if err != nil {
+				return err
+			}
+
+			return prettyPrint(report)
+		},
+	}
+
+	cmd.Flags().StringVarP(&format, "format", "f", string(importer.FormatCSV), "one of csv or clz")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "report matches without writing to the user store")
+
+	return cmd
+}
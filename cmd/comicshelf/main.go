@@ -54,6 +54,8 @@ func main() {
 					mapstructure.StringToSliceHookFunc(","),
 					hooks.UrlHook(),
 					hooks.SlogLevelHook(),
+					hooks.WeekdayHook(),
+					hooks.LocationHook(),
 				),
 				Result: &cfg,
 			})
@@ -78,6 +80,7 @@ func main() {
 
 	rootCmd.AddCommand(serverCmd())
 	rootCmd.AddCommand(marvelCmd())
+	rootCmd.AddCommand(importCmd())
 
 	cobra.CheckErr(rootCmd.Execute())
 }
@@ -1,14 +1,21 @@
 package main
 
 import (
+	"github.com/jakedegiovanni/comicshelf/internal/catalogue"
 	"github.com/jakedegiovanni/comicshelf/internal/filedb"
+	"github.com/jakedegiovanni/comicshelf/internal/lifecycle"
+	"github.com/jakedegiovanni/comicshelf/internal/metrics"
+	"github.com/jakedegiovanni/comicshelf/internal/search"
 	"github.com/jakedegiovanni/comicshelf/internal/server"
+	"github.com/jakedegiovanni/comicshelf/internal/tracing"
+	"github.com/jakedegiovanni/comicshelf/internal/unlimited"
 	"github.com/jakedegiovanni/comicshelf/marvel"
 	"github.com/spf13/cobra"
 )
 
 func serverCmd() *cobra.Command {
 	// todo configure strategy?
+	var dev bool
 
 	server := &cobra.Command{
 		Use: "server",
@@ -18,22 +25,57 @@ func serverCmd() *cobra.Command {
 				return err
 			}
 
-			userSvc, err := filedb.New(&cfg.FileDB)
+			if dev {
+				cfg.Server.Dev = true
+			}
+
+			lc := lifecycle.New(&cfg.Lifecycle)
+
+			shutdownTracing, err := tracing.Setup(cmd.Context(), &cfg.Tracing)
 			if err != nil {
 				return err
 			}
-			defer userSvc.Shutdown()
+			lc.OnStop("tracing", shutdownTracing)
+
+			db, err := filedb.New(&cfg.FileDB)
+			if err != nil {
+				return lc.Stop(err)
+			}
+			lc.OnStop("filedb", db.Shutdown)
+
+			userSvc := tracing.NewUserService(db)
+
+			marvelSvc := marvel.New(&cfg.Marvel, metrics.ClientMiddleware(), tracing.ClientMiddleware())
+
+			index := search.NewIndex()
+			searchSvc := search.New(marvelSvc, index)
+
+			predictor := unlimited.New(&cfg.Unlimited)
+
+			catalogueSvc, err := catalogue.New(&cfg.Catalogue, marvelSvc, catalogue.Listeners{index, predictor})
+			if err != nil {
+				return lc.Stop(err)
+			}
+			lc.OnStop("catalogue", catalogueSvc.Shutdown)
+			catalogueSvc.Start()
 
-			marvelSvc := marvel.New(&cfg.Marvel)
+			comicSvc := unlimited.NewService(catalogueSvc, catalogueSvc, predictor)
 
-			svc, err := server.New(&cfg.Server, marvelSvc, marvelSvc, userSvc)
+			svc, err := server.New(&cfg.Server, comicSvc, comicSvc, marvelSvc, marvelSvc, searchSvc, userSvc, db)
 			if err != nil {
-				return err
+				return lc.Stop(err)
 			}
 
-			return svc.Run(cmd.Context())
+			svc.AddCheck("filedb_writable", db.CheckWritable)
+			svc.AddCheck("filedb_flushed", db.CheckFlushed)
+			svc.AddCheck("marvel_credentials", marvel.CheckCredentials)
+			svc.AddCheck("marvel_circuit", marvelSvc.CheckCircuit)
+
+			return lc.Run(cmd.Context(), svc.Run)
 		},
 	}
 
+	server.Flags().BoolVar(&dev, "dev", false, "serve templates and static files from disk and reload them on change")
+
 	return server
 }
@@ -0,0 +1,60 @@
+package hooks
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/mitchellh/mapstructure"
+)
+
+func WeekdayHook() mapstructure.DecodeHookFuncType {
+	return func(src, target reflect.Type, data interface{}) (interface{}, error) {
+		if src.Kind() != reflect.String {
+			return data, nil
+		}
+
+		if target != reflect.TypeOf(time.Weekday(0)) {
+			return data, nil
+		}
+
+		dat, ok := data.(string)
+		if !ok {
+			return nil, errors.New("could not cast decode source to string")
+		}
+
+		for day := time.Sunday; day <= time.Saturday; day++ {
+			if strings.EqualFold(day.String(), dat) {
+				return day, nil
+			}
+		}
+
+		return nil, fmt.Errorf("could not parse weekday for config decoding: %s", dat)
+	}
+}
+
+func LocationHook() mapstructure.DecodeHookFuncType {
+	return func(src, target reflect.Type, data interface{}) (interface{}, error) {
+		if src.Kind() != reflect.String {
+			return data, nil
+		}
+
+		if target != reflect.TypeOf(&time.Location{}) {
+			return data, nil
+		}
+
+		dat, ok := data.(string)
+		if !ok {
+			return nil, errors.New("could not cast decode source to string")
+		}
+
+		loc, err := time.LoadLocation(dat)
+		if err != nil {
+			return nil, fmt.Errorf("could not load timezone for config decoding: %w", err)
+		}
+
+		return loc, nil
+	}
+}
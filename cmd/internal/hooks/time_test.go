@@ -0,0 +1,38 @@
+package hooks
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mitchellh/mapstructure"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTimeConfig(t *testing.T) {
+	v := viper.New()
+
+	v.Set("weekday", "Monday")
+	v.Set("timezone", "America/New_York")
+
+	var c struct {
+		Weekday  time.Weekday   `mapstructure:"weekday"`
+		Timezone *time.Location `mapstructure:"timezone"`
+	}
+	require.Nil(t, v.Unmarshal(&c, viper.DecodeHook(mapstructure.ComposeDecodeHookFunc(WeekdayHook(), LocationHook()))))
+
+	assert.Equal(t, time.Monday, c.Weekday)
+	assert.Equal(t, "America/New_York", c.Timezone.String())
+}
+
+func TestWeekdayInvalid(t *testing.T) {
+	v := viper.New()
+
+	v.Set("weekday", "someday")
+
+	var c struct {
+		Weekday time.Weekday `mapstructure:"weekday"`
+	}
+	assert.NotNil(t, v.Unmarshal(&c, viper.DecodeHook(WeekdayHook())))
+}
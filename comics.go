@@ -2,23 +2,95 @@ package comicshelf
 
 import (
 	"context"
+	"slices"
+	"strings"
 	"time"
 )
 
+// Comic is a single issue. Its dates are calendar days held as midnight UTC, they are not instants to convert between zones.
 type Comic struct {
-	Id              int       `json:"id"`
-	Title           string    `json:"title"`
-	Urls            []Url     `json:"urls"`
-	Thumbnail       string    `json:"thumbnail"`
-	Format          string    `json:"format"`
-	IssuerNumber    int       `json:"issuer_number"`
-	OnSaleDate      time.Time `json:"on_sale_date"`
-	Attribution     string    `json:"attribution"`
-	AttributionLink string    `json:"attribution_link"`
-	SeriesId        int       `json:"series_id"`
+	Id                 int         `json:"id"`
+	Title              string      `json:"title"`
+	Description        string      `json:"description"`
+	Urls               []Url       `json:"urls"`
+	Thumbnail          string      `json:"thumbnail"`
+	Format             string      `json:"format"`
+	IssuerNumber       int         `json:"issuer_number"`
+	PageCount          int         `json:"page_count"`
+	Prices             []Price     `json:"prices"`
+	VariantDescription string      `json:"variant_description"`
+	Variants           []Reference `json:"variants"`
+	Creators           []Credit    `json:"creators"`
+	Characters         []Reference `json:"characters"`
+	OnSaleDate         time.Time   `json:"on_sale_date"`
+	UnlimitedDate      time.Time   `json:"unlimited_date"`
+	Attribution        string      `json:"attribution"`
+	AttributionLink    string      `json:"attribution_link"`
+	SeriesId           int         `json:"series_id"`
+	// UnlimitedPrediction estimates the unlimited date while it is still unknown.
+	UnlimitedPrediction *Prediction `json:"unlimited_prediction,omitempty"`
+}
+
+// Prediction is an estimated date, drawn from how long similar issues took.
+type Prediction struct {
+	Date time.Time `json:"date"`
+	// Confidence is between 0 and 1, growing with the number of issues behind it and how consistent they were.
+	Confidence float64 `json:"confidence"`
+	Samples    int     `json:"samples"`
+	// Basis is what the issues behind the prediction have in common with this one, such as the series or format.
+	Basis string `json:"basis"`
+}
+
+type Price struct {
+	Type  string  `json:"type"`
+	Price float64 `json:"price"`
+}
+
+// ComicFilter narrows a list of comics, its zero value lets every comic through.
+type ComicFilter struct {
+	// Creator and Character match any credited name containing them, ignoring case.
+	Creator   string
+	Character string
+	// MinPages and MaxPrice are ignored while zero, comics with no page count or price never match them.
+	MinPages int
+	MaxPrice float64
+}
+
+// Matches reports whether c passes every part of the filter that is set.
+func (f ComicFilter) Matches(c Comic) bool {
+	if f.Creator != "" && !slices.ContainsFunc(c.Creators, func(credit Credit) bool {
+		return containsFold(credit.Name, f.Creator)
+	}) {
+		return false
+	}
+
+	if f.Character != "" && !slices.ContainsFunc(c.Characters, func(character Reference) bool {
+		return containsFold(character.Name, f.Character)
+	}) {
+		return false
+	}
+
+	if f.MinPages > 0 && c.PageCount < f.MinPages {
+		return false
+	}
+
+	if f.MaxPrice > 0 && !slices.ContainsFunc(c.Prices, func(p Price) bool {
+		return p.Price > 0 && p.Price <= f.MaxPrice
+	}) {
+		return false
+	}
+
+	return true
+}
+
+func containsFold(s, substr string) bool {
+	return strings.Contains(strings.ToLower(s), strings.ToLower(substr))
 }
 
 type ComicService interface {
+	// GetWeeklyComics returns the comics that become available on Marvel Unlimited during the week containing t.
 	GetWeeklyComics(ctx context.Context, t time.Time) (Page[Comic], error)
+	// GetReleasedComics returns the comics released in print during the week containing t.
+	GetReleasedComics(ctx context.Context, t time.Time) (Page[Comic], error)
 	GetComic(ctx context.Context, id int) (Comic, error)
 }
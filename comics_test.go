@@ -0,0 +1,43 @@
+package comicshelf
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestComicFilterMatches(t *testing.T) {
+	comic := Comic{
+		PageCount:  32,
+		Prices:     []Price{{Type: "printPrice", Price: 4.99}, {Type: "digitalPurchasePrice", Price: 0}},
+		Creators:   []Credit{{Reference: Reference{Id: 1, Name: "Jonathan Hickman"}, Role: "writer"}},
+		Characters: []Reference{{Id: 2, Name: "Spider-Man (Peter Parker)"}},
+	}
+
+	tests := []struct {
+		name   string
+		filter ComicFilter
+		comic  Comic
+		want   bool
+	}{
+		{name: "zero value", comic: comic, want: true},
+		{name: "zero value on an empty comic", comic: Comic{}, want: true},
+		{name: "creator ignores case", filter: ComicFilter{Creator: "hickman"}, comic: comic, want: true},
+		{name: "creator not credited", filter: ComicFilter{Creator: "Claremont"}, comic: comic, want: false},
+		{name: "character substring", filter: ComicFilter{Character: "peter parker"}, comic: comic, want: true},
+		{name: "character missing", filter: ComicFilter{Character: "Wolverine"}, comic: comic, want: false},
+		{name: "enough pages", filter: ComicFilter{MinPages: 32}, comic: comic, want: true},
+		{name: "too few pages", filter: ComicFilter{MinPages: 33}, comic: comic, want: false},
+		{name: "no page count", filter: ComicFilter{MinPages: 1}, comic: Comic{}, want: false},
+		{name: "within price", filter: ComicFilter{MaxPrice: 4.99}, comic: comic, want: true},
+		{name: "over price", filter: ComicFilter{MaxPrice: 3.99}, comic: comic, want: false},
+		{name: "a zero price never matches", filter: ComicFilter{MaxPrice: 3.99}, comic: Comic{Prices: []Price{{Price: 0}}}, want: false},
+		{name: "every part must match", filter: ComicFilter{Creator: "Hickman", MinPages: 40}, comic: comic, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.filter.Matches(tt.comic))
+		})
+	}
+}
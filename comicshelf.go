@@ -12,3 +12,13 @@ type Url struct {
 	Type string `json:"type"`
 	Url  string `json:"url"`
 }
+
+type Reference struct {
+	Id   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+type Credit struct {
+	Reference
+	Role string `json:"role"`
+}
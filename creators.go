@@ -0,0 +1,18 @@
+package comicshelf
+
+import (
+	"context"
+	"time"
+)
+
+type Creator struct {
+	Id        int    `json:"id"`
+	FullName  string `json:"full_name"`
+	Urls      []Url  `json:"urls"`
+	Thumbnail string `json:"thumbnail"`
+}
+
+type CreatorService interface {
+	GetCreator(ctx context.Context, id int) (Creator, error)
+	GetComicsByCreator(ctx context.Context, id int, from, to time.Time) ([]Comic, error)
+}
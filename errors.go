@@ -0,0 +1,13 @@
+package comicshelf
+
+import "errors"
+
+// Sentinel errors shared by every service implementation. Implementations wrap these with
+// additional context so callers can use errors.Is to decide how to respond.
+var (
+	ErrNotFound     = errors.New("not found")
+	ErrUpstream     = errors.New("upstream error")
+	ErrInvalidInput = errors.New("invalid input")
+	// ErrConflict is a change made against state that has since moved on, such as a page that needs reloading.
+	ErrConflict = errors.New("conflict")
+)
@@ -0,0 +1,215 @@
+package catalogue
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/jakedegiovanni/comicshelf"
+)
+
+var _ comicshelf.ComicService = (*Catalogue)(nil)
+var _ comicshelf.SeriesService = (*Catalogue)(nil)
+
+// Upstream is the provider the catalogue is filled from, both on demand and by crawling.
+type Upstream interface {
+	comicshelf.ComicService
+	comicshelf.SeriesService
+	// ComicsModifiedSince crawls the issues GetWeeklyComics lists, and WeeklyRange gives the on sale days it lists at a
+	// time, so a crawled week can be listed without asking the upstream.
+	ComicsModifiedSince(ctx context.Context, since time.Time, offset int) (comicshelf.Page[comicshelf.Comic], error)
+	WeeklyRange(t time.Time) (time.Time, time.Time)
+	SeriesModifiedSince(ctx context.Context, since time.Time, offset int) (comicshelf.Page[comicshelf.Series], error)
+}
+
+// Listener is told about everything that enters the catalogue, such as a search index.
+type Listener interface {
+	AddComics(comics ...comicshelf.Comic)
+	AddSeries(series ...comicshelf.Series)
+}
+
+// Listeners tells every listener in turn.
+type Listeners []Listener
+
+func (l Listeners) AddComics(comics ...comicshelf.Comic) {
+	for _, listener := range l {
+		listener.AddComics(comics...)
+	}
+}
+
+func (l Listeners) AddSeries(series ...comicshelf.Series) {
+	for _, listener := range l {
+		listener.AddSeries(series...)
+	}
+}
+
+// Catalogue serves comics and series from a local store, which a background crawl keeps in sync with the upstream.
+// Anything not yet in the store is fetched from the upstream and kept.
+type Catalogue struct {
+	cfg      *Config
+	upstream Upstream
+	store    *store
+	listener Listener
+	quit     chan bool
+	crawling sync.WaitGroup
+}
+
+// New loads the catalogue from disk, replaying everything already stored to the listener.
+func New(cfg *Config, upstream Upstream, listener Listener) (*Catalogue, error) {
+	s, err := newStore(cfg.Filename)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &Catalogue{
+		cfg:      cfg,
+		upstream: upstream,
+		store:    s,
+		listener: listener,
+		quit:     make(chan bool),
+	}
+
+	listener.AddComics(s.allComics()...)
+	listener.AddSeries(s.allSeries()...)
+	return c, nil
+}
+
+func (c *Catalogue) Start() {
+	if c.cfg.SyncInterval <= 0 {
+		slog.Info("catalogue sync disabled")
+		return
+	}
+
+	c.crawl()
+}
+
+// Shutdown stops the crawl, waiting for a sync in progress to wind down, then saves the catalogue.
+func (c *Catalogue) Shutdown(ctx context.Context) error {
+	slog.Debug("shutting down catalogue")
+	close(c.quit)
+
+	crawled := make(chan struct{})
+	go func() {
+		c.crawling.Wait()
+		close(crawled)
+	}()
+
+	var errs []error
+	select {
+	case <-crawled:
+	case <-ctx.Done():
+		// the store is safe to save mid sync, so still keep what has been crawled so far
+		errs = append(errs, fmt.Errorf("crawl did not stop: %w", ctx.Err()))
+	}
+
+	err := c.store.flush()
+	if err != nil {
+		errs = append(errs, fmt.Errorf("catalogue save error: %w", err))
+	}
+
+	return errors.Join(errs...)
+}
+
+// GetWeeklyComics lists a week from the store once the crawl has seen every change made to its issues from before they
+// went on sale until after the week ended. Other weeks come from the upstream.
+func (c *Catalogue) GetWeeklyComics(ctx context.Context, t time.Time) (comicshelf.Page[comicshelf.Comic], error) {
+	first, last := c.upstream.WeeklyRange(t)
+	if c.store.cursor(comicsCursor).covers(first, last.AddDate(0, 0, 1)) {
+		comics := c.store.crawledBetween(first, last)
+		return comicshelf.Page[comicshelf.Comic]{
+			Limit:   len(comics),
+			Total:   len(comics),
+			Count:   len(comics),
+			Results: comics,
+		}, nil
+	}
+
+	comics, err := c.upstream.GetWeeklyComics(ctx, t)
+	if err != nil {
+		return comicshelf.Page[comicshelf.Comic]{}, err
+	}
+
+	c.store.putComics(comics.Results...)
+	c.listener.AddComics(comics.Results...)
+	return comics, nil
+}
+
+// GetReleasedComics always asks the upstream, the crawl only covers the issues with a digital release.
+func (c *Catalogue) GetReleasedComics(ctx context.Context, t time.Time) (comicshelf.Page[comicshelf.Comic], error) {
+	comics, err := c.upstream.GetReleasedComics(ctx, t)
+	if err != nil {
+		return comicshelf.Page[comicshelf.Comic]{}, err
+	}
+
+	c.store.putComics(comics.Results...)
+	c.listener.AddComics(comics.Results...)
+	return comics, nil
+}
+
+// GetComic serves a stored comic until it is older than the configured max age, then fetches it again.
+// A stale copy is still served while the upstream is failing.
+func (c *Catalogue) GetComic(ctx context.Context, id int) (comicshelf.Comic, error) {
+	stored, fetched, ok := c.store.comic(id)
+	if ok && (c.cfg.MaxAge <= 0 || time.Since(fetched) < c.cfg.MaxAge) {
+		return stored, nil
+	}
+
+	comic, err := c.upstream.GetComic(ctx, id)
+	if err != nil {
+		if ok && errors.Is(err, comicshelf.ErrUpstream) {
+			slog.Warn("serving stale comic", slog.Int("comic", id), slog.String("err", err.Error()))
+			return stored, nil
+		}
+		return comicshelf.Comic{}, err
+	}
+
+	c.store.putComics(comic)
+	c.listener.AddComics(comic)
+	return comic, nil
+}
+
+func (c *Catalogue) GetComicsWithinSeries(ctx context.Context, id int) ([]comicshelf.Comic, error) {
+	if comics, complete := c.store.comicsWithinSeries(id); complete {
+		return comics, nil
+	}
+
+	comics, err := c.upstream.GetComicsWithinSeries(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	c.store.putListedComics(comics...)
+	c.store.markComplete(id)
+	c.listener.AddComics(comics...)
+	return comics, nil
+}
+
+func (c *Catalogue) GetSeries(ctx context.Context, id int) (comicshelf.Series, error) {
+	if series, ok := c.store.series(id); ok {
+		comics, err := c.GetComicsWithinSeries(ctx, id)
+		if err == nil {
+			series.Comics = comics
+			return series, nil
+		}
+
+		if !errors.Is(err, comicshelf.ErrUpstream) {
+			return comicshelf.Series{}, err
+		}
+		slog.Warn("serving series without comics", slog.Int("series", id), slog.String("err", err.Error()))
+		return series, nil
+	}
+
+	series, err := c.upstream.GetSeries(ctx, id)
+	if err != nil {
+		return comicshelf.Series{}, err
+	}
+
+	c.store.putSeries(series)
+	c.store.putComics(series.Comics...)
+	c.listener.AddSeries(series)
+	c.listener.AddComics(series.Comics...)
+	return series, nil
+}
@@ -0,0 +1,290 @@
+package catalogue
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jakedegiovanni/comicshelf"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeUpstream struct {
+	comics       []comicshelf.Comic
+	seriesCalls  int
+	crawlOffsets []int
+	weeklyCalls  int
+	comicCalls   int
+	comicErr     error
+}
+
+func (f *fakeUpstream) GetWeeklyComics(ctx context.Context, t time.Time) (comicshelf.Page[comicshelf.Comic], error) {
+	f.weeklyCalls++
+	return comicshelf.Page[comicshelf.Comic]{}, nil
+}
+
+// WeeklyRange lists the week starting on the day of t, worked out in a zone behind UTC as a provider's would be.
+func (f *fakeUpstream) WeeklyRange(t time.Time) (time.Time, time.Time) {
+	first := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.FixedZone("UTC-5", -5*60*60))
+	return first, first.AddDate(0, 0, 6)
+}
+
+func (f *fakeUpstream) GetReleasedComics(ctx context.Context, t time.Time) (comicshelf.Page[comicshelf.Comic], error) {
+	return comicshelf.Page[comicshelf.Comic]{}, nil
+}
+
+func (f *fakeUpstream) GetComic(ctx context.Context, id int) (comicshelf.Comic, error) {
+	f.comicCalls++
+	if f.comicErr != nil {
+		return comicshelf.Comic{}, f.comicErr
+	}
+	return comicshelf.Comic{Id: id, Title: "Fetched"}, nil
+}
+
+func (f *fakeUpstream) GetComicsWithinSeries(ctx context.Context, id int) ([]comicshelf.Comic, error) {
+	f.seriesCalls++
+	return f.comics, nil
+}
+
+func (f *fakeUpstream) GetSeries(ctx context.Context, id int) (comicshelf.Series, error) {
+	return comicshelf.Series{Id: id}, nil
+}
+
+func (f *fakeUpstream) ComicsModifiedSince(ctx context.Context, since time.Time, offset int) (comicshelf.Page[comicshelf.Comic], error) {
+	f.crawlOffsets = append(f.crawlOffsets, offset)
+	if offset >= len(f.comics) {
+		return comicshelf.Page[comicshelf.Comic]{Total: len(f.comics)}, nil
+	}
+
+	return comicshelf.Page[comicshelf.Comic]{
+		Total:   len(f.comics),
+		Count:   1,
+		Offset:  offset,
+		Results: f.comics[offset : offset+1],
+	}, nil
+}
+
+func (f *fakeUpstream) SeriesModifiedSince(ctx context.Context, since time.Time, offset int) (comicshelf.Page[comicshelf.Series], error) {
+	return comicshelf.Page[comicshelf.Series]{}, nil
+}
+
+type nopListener struct{}
+
+func (nopListener) AddComics(...comicshelf.Comic)  {}
+func (nopListener) AddSeries(...comicshelf.Series) {}
+
+func newTestCatalogue(t *testing.T, upstream Upstream, maxPages int) *Catalogue {
+	c, err := New(&Config{
+		Filename: filepath.Join(t.TempDir(), "catalogue.json"),
+		Lookback: time.Hour,
+		MaxPages: maxPages,
+	}, upstream, nopListener{})
+	require.Nil(t, err)
+	return c
+}
+
+func TestComicsWithinSeriesServedLocally(t *testing.T) {
+	upstream := &fakeUpstream{comics: []comicshelf.Comic{
+		{Id: 2, SeriesId: 1, IssuerNumber: 2},
+		{Id: 1, SeriesId: 1, IssuerNumber: 1},
+	}}
+	c := newTestCatalogue(t, upstream, 1)
+
+	_, err := c.GetComicsWithinSeries(context.Background(), 1)
+	require.Nil(t, err)
+
+	comics, err := c.GetComicsWithinSeries(context.Background(), 1)
+	require.Nil(t, err)
+
+	assert.Equal(t, 1, upstream.seriesCalls)
+	assert.Equal(t, []int{1, 2}, []int{comics[0].Id, comics[1].Id})
+}
+
+func TestSyncResumesPartialPass(t *testing.T) {
+	upstream := &fakeUpstream{comics: []comicshelf.Comic{
+		{Id: 1, SeriesId: 1},
+		{Id: 2, SeriesId: 1},
+		{Id: 3, SeriesId: 1},
+	}}
+	c := newTestCatalogue(t, upstream, 2)
+
+	c.sync()
+	assert.Equal(t, []int{0, 1}, upstream.crawlOffsets)
+	assert.Equal(t, 2, c.store.cursor(comicsCursor).Offset)
+
+	c.sync()
+	assert.Equal(t, []int{0, 1, 2}, upstream.crawlOffsets)
+
+	cur := c.store.cursor(comicsCursor)
+	assert.Equal(t, 0, cur.Offset)
+	assert.True(t, cur.Started.IsZero())
+	assert.False(t, cur.Since.IsZero())
+
+	comics, complete := c.store.comicsWithinSeries(1)
+	assert.Len(t, comics, 3)
+	assert.False(t, complete)
+}
+
+func TestSyncWithoutPageLimit(t *testing.T) {
+	upstream := &fakeUpstream{comics: []comicshelf.Comic{
+		{Id: 1, SeriesId: 1},
+		{Id: 2, SeriesId: 1},
+		{Id: 3, SeriesId: 1},
+	}}
+	c := newTestCatalogue(t, upstream, 0)
+
+	c.sync()
+	assert.Equal(t, []int{0, 1, 2}, upstream.crawlOffsets)
+
+	cur := c.store.cursor(comicsCursor)
+	assert.Equal(t, 0, cur.Offset)
+	assert.True(t, cur.Started.IsZero())
+}
+
+func TestStoreSavesAndReloads(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "catalogue.json")
+
+	s, err := newStore(filename)
+	require.Nil(t, err)
+	s.putComics(comicshelf.Comic{Id: 1, SeriesId: 1, Title: "Saved"})
+	require.Nil(t, s.flush())
+
+	s, err = newStore(filename)
+	require.Nil(t, err)
+	comic, _, ok := s.comic(1)
+	assert.True(t, ok)
+	assert.Equal(t, "Saved", comic.Title)
+
+	entries, err := os.ReadDir(filepath.Dir(filename))
+	require.Nil(t, err)
+	assert.Len(t, entries, 1, "no temporary files are left behind")
+}
+
+func TestStoreStartsEmptyFromBrokenFile(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "catalogue.json")
+	require.Nil(t, os.WriteFile(filename, []byte(`{"comics": {"1": {"id": 1`), 0644))
+
+	s, err := newStore(filename)
+	require.Nil(t, err)
+	assert.Empty(t, s.allComics())
+
+	s.putComics(comicshelf.Comic{Id: 2, SeriesId: 1})
+	require.Nil(t, s.flush())
+
+	s, err = newStore(filename)
+	require.Nil(t, err)
+	_, _, ok := s.comic(2)
+	assert.True(t, ok)
+}
+
+func TestGetComicMaxAge(t *testing.T) {
+	tests := []struct {
+		name    string
+		maxAge  time.Duration
+		age     time.Duration
+		err     error
+		calls   int
+		title   string
+		wantErr error
+	}{
+		{name: "fresh", maxAge: time.Hour, age: time.Minute, title: "Stored"},
+		{name: "no max age", age: 365 * 24 * time.Hour, title: "Stored"},
+		{name: "stale", maxAge: time.Hour, age: 2 * time.Hour, calls: 1, title: "Fetched"},
+		{name: "stale while upstream fails", maxAge: time.Hour, age: 2 * time.Hour, err: comicshelf.ErrUpstream, calls: 1, title: "Stored"},
+		{name: "stale and gone", maxAge: time.Hour, age: 2 * time.Hour, err: comicshelf.ErrNotFound, calls: 1, wantErr: comicshelf.ErrNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			upstream := &fakeUpstream{comicErr: tt.err}
+			c := newTestCatalogue(t, upstream, 1)
+			c.cfg.MaxAge = tt.maxAge
+
+			c.store.putComics(comicshelf.Comic{Id: 1, Title: "Stored"})
+			c.store.data.Fetched[1] = time.Now().Add(-tt.age)
+
+			comic, err := c.GetComic(context.Background(), 1)
+			assert.Equal(t, tt.calls, upstream.comicCalls)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.Nil(t, err)
+			assert.Equal(t, tt.title, comic.Title)
+		})
+	}
+}
+
+func TestWeeklyComicsFromCrawl(t *testing.T) {
+	week := time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		cursor cursor
+		calls  int
+		want   []int
+	}{
+		{
+			name:   "crawled",
+			cursor: cursor{Origin: week.AddDate(0, 0, -30), Since: week.AddDate(0, 0, 10)},
+			want:   []int{2, 1},
+		},
+		{
+			name:   "week not over when last crawled",
+			cursor: cursor{Origin: week.AddDate(0, 0, -30), Since: week.AddDate(0, 0, 3)},
+			calls:  1,
+		},
+		{
+			name:   "crawl started after the week",
+			cursor: cursor{Origin: week.AddDate(0, 0, 1), Since: week.AddDate(0, 0, 10)},
+			calls:  1,
+		},
+		{
+			name:   "never crawled",
+			cursor: cursor{Since: week.AddDate(0, 0, 10)},
+			calls:  1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			upstream := &fakeUpstream{}
+			c := newTestCatalogue(t, upstream, 1)
+
+			c.store.putCrawledComics(
+				comicshelf.Comic{Id: 1, IssuerNumber: 3, OnSaleDate: week.AddDate(0, 0, 6)},
+				comicshelf.Comic{Id: 2, IssuerNumber: 1, OnSaleDate: week},
+				comicshelf.Comic{Id: 3, IssuerNumber: 2, OnSaleDate: week.AddDate(0, 0, 7)},
+			)
+			// fetched on its own rather than crawled, so not part of any weekly listing
+			c.store.putComics(comicshelf.Comic{Id: 4, OnSaleDate: week.AddDate(0, 0, 1)})
+			c.store.setCursor(comicsCursor, tt.cursor)
+
+			comics, err := c.GetWeeklyComics(context.Background(), week)
+			require.Nil(t, err)
+			assert.Equal(t, tt.calls, upstream.weeklyCalls)
+
+			var ids []int
+			for _, comic := range comics.Results {
+				ids = append(ids, comic.Id)
+			}
+			assert.Equal(t, tt.want, ids)
+		})
+	}
+}
+
+func TestSyncKeepsOrigin(t *testing.T) {
+	upstream := &fakeUpstream{comics: []comicshelf.Comic{{Id: 1, SeriesId: 1}}}
+	c := newTestCatalogue(t, upstream, 0)
+
+	c.sync()
+	first := c.store.cursor(comicsCursor)
+	assert.False(t, first.Origin.IsZero())
+	assert.True(t, first.Origin.Before(first.Since))
+
+	c.sync()
+	assert.Equal(t, first.Origin, c.store.cursor(comicsCursor).Origin)
+}
@@ -0,0 +1,16 @@
+package catalogue
+
+import "time"
+
+type Config struct {
+	Filename     string        `mapstructure:"filename"`
+	SyncInterval time.Duration `mapstructure:"sync_interval"`
+	// Lookback is how far back the very first crawl starts from, later crawls carry on from the last sync.
+	Lookback time.Duration `mapstructure:"lookback"`
+	// MaxPages bounds how many pages of each kind a single sync fetches, a partial sync resumes on the next run.
+	// Zero or less leaves a sync to run until the upstream has nothing more.
+	MaxPages int `mapstructure:"max_pages"`
+	// MaxAge is how long a stored comic is served before it is fetched again, zero or less keeps it until the crawl
+	// replaces it.
+	MaxAge time.Duration `mapstructure:"max_age"`
+}
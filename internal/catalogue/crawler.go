@@ -0,0 +1,111 @@
+package catalogue
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/jakedegiovanni/comicshelf"
+)
+
+func (c *Catalogue) crawl() {
+	c.crawling.Add(1)
+	go func() {
+		defer c.crawling.Done()
+		c.sync()
+
+		for {
+			timer := time.NewTimer(c.cfg.SyncInterval)
+			select {
+			case <-c.quit:
+				timer.Stop()
+				return
+			case <-timer.C:
+				c.sync()
+			}
+		}
+	}()
+}
+
+func (c *Catalogue) sync() {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go func() {
+		select {
+		case <-c.quit:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	slog.Debug("catalogue sync starting")
+
+	err := syncCursor(ctx, c, comicsCursor, c.upstream.ComicsModifiedSince, func(comics []comicshelf.Comic) {
+		c.store.putCrawledComics(comics...)
+		c.listener.AddComics(comics...)
+	})
+	if err != nil {
+		slog.Error("catalogue comics sync", slog.String("err", err.Error()))
+	}
+
+	err = syncCursor(ctx, c, seriesCursor, c.upstream.SeriesModifiedSince, func(series []comicshelf.Series) {
+		c.store.putSeries(series...)
+		c.listener.AddSeries(series...)
+	})
+	if err != nil {
+		slog.Error("catalogue series sync", slog.String("err", err.Error()))
+	}
+
+	err = c.store.flush()
+	if err != nil {
+		slog.Error("catalogue save error", slog.String("err", err.Error()))
+		return
+	}
+
+	slog.Debug("catalogue sync finished")
+}
+
+func syncCursor[T any](
+	ctx context.Context,
+	c *Catalogue,
+	name string,
+	fetch func(context.Context, time.Time, int) (comicshelf.Page[T], error),
+	put func([]T),
+) error {
+	cur := c.store.cursor(name)
+	if cur.Since.IsZero() {
+		cur.Since = time.Now().UTC().Add(-c.cfg.Lookback)
+		cur.Origin = cur.Since
+	}
+
+	if cur.Origin.IsZero() {
+		// a cursor saved before origins were kept is only trusted from its last finished pass on
+		cur.Origin = cur.Since
+	}
+
+	if cur.Started.IsZero() {
+		cur.Started = time.Now().UTC()
+	}
+
+	for i := 0; c.cfg.MaxPages <= 0 || i < c.cfg.MaxPages; i++ {
+		page, err := fetch(ctx, cur.Since, cur.Offset)
+		if err != nil {
+			return err
+		}
+
+		put(page.Results)
+		cur.Offset += page.Count
+
+		if page.Count == 0 || cur.Offset >= page.Total {
+			cur = cursor{Since: cur.Started, Origin: cur.Origin}
+			break
+		}
+
+		// persisted as it goes so a partial pass resumes where it left off
+		c.store.setCursor(name, cur)
+	}
+
+	c.store.setCursor(name, cur)
+	return nil
+}
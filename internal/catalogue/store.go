@@ -0,0 +1,292 @@
+package catalogue
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/jakedegiovanni/comicshelf"
+)
+
+const (
+	comicsCursor = "comics"
+	seriesCursor = "series"
+)
+
+// cursor tracks progress through a crawl. A pass fetches everything modified since Since, page by page, and once it
+// reaches the end Since moves forward to when the pass started.
+type cursor struct {
+	Since   time.Time `json:"since"`
+	Offset  int       `json:"offset"`
+	Started time.Time `json:"started"`
+	// Origin is where the first pass started from, everything modified between it and Since has been crawled.
+	Origin time.Time `json:"origin"`
+}
+
+// covers reports whether every change between from and to has been crawled.
+func (c cursor) covers(from, to time.Time) bool {
+	return !c.Origin.IsZero() && !from.Before(c.Origin) && !to.After(c.Since)
+}
+
+type data struct {
+	Comics map[int]comicshelf.Comic  `json:"comics"`
+	Series map[int]comicshelf.Series `json:"series"`
+	// SeriesComics holds the comics known to belong to a series listing, which excludes variants and the like that
+	// can still be fetched individually.
+	SeriesComics map[int]comicshelf.Set[int] `json:"series_comics"`
+	// Complete marks series whose full listing has been fetched, so it can be served without the upstream.
+	Complete comicshelf.Set[int] `json:"complete"`
+	// Crawled holds the comics the crawl has listed, the same issues as an upstream weekly listing.
+	Crawled comicshelf.Set[int] `json:"crawled"`
+	// Fetched is when each comic was last stored, a comic without one is treated as stale.
+	Fetched map[int]time.Time `json:"fetched"`
+	Cursors map[string]cursor `json:"cursors"`
+}
+
+func newData() data {
+	return data{
+		Comics:       make(map[int]comicshelf.Comic),
+		Series:       make(map[int]comicshelf.Series),
+		SeriesComics: make(map[int]comicshelf.Set[int]),
+		Complete:     make(comicshelf.Set[int]),
+		Crawled:      make(comicshelf.Set[int]),
+		Fetched:      make(map[int]time.Time),
+		Cursors:      make(map[string]cursor),
+	}
+}
+
+type store struct {
+	filename string
+	data     data
+	mu       *sync.RWMutex
+}
+
+func newStore(filename string) (*store, error) {
+	d := newData()
+
+	b, err := os.ReadFile(filename)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+
+	if len(b) > 0 {
+		// decoded over empty maps so fields missing from an older file are still usable
+		loaded := newData()
+		err = json.Unmarshal(b, &loaded)
+		if err != nil {
+			// only a cache of the upstream, so it is rebuilt rather than keeping the server from starting
+			slog.Warn("could not decode catalogue, starting empty", slog.String("file", filename), slog.String("err", err.Error()))
+		} else {
+			d = loaded
+		}
+	}
+
+	return &store{
+		filename: filename,
+		data:     d,
+		mu:       new(sync.RWMutex),
+	}, nil
+}
+
+// flush writes the catalogue aside and renames it into place, so a crash mid write leaves the last save intact.
+func (s *store) flush() error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	f, err := os.CreateTemp(filepath.Dir(s.filename), filepath.Base(s.filename)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name()) // a no-op once renamed
+
+	err = f.Chmod(0644)
+	if err == nil {
+		err = json.NewEncoder(f).Encode(s.data)
+	}
+	if err == nil {
+		err = f.Sync()
+	}
+	if err != nil {
+		f.Close()
+		return err
+	}
+
+	err = f.Close()
+	if err != nil {
+		return err
+	}
+
+	err = os.Rename(f.Name(), s.filename)
+	if err != nil {
+		return fmt.Errorf("could not replace catalogue: %w", err)
+	}
+
+	slog.Debug("catalogue saved", slog.Int("comics", len(s.data.Comics)), slog.Int("series", len(s.data.Series)))
+	return nil
+}
+
+// comic returns a stored comic along with when it was stored.
+func (s *store) comic(id int) (comicshelf.Comic, time.Time, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	c, ok := s.data.Comics[id]
+	return c, s.data.Fetched[id], ok
+}
+
+// crawledBetween returns the crawled comics on sale from the day of first to the day of last, ordered by issue
+// number as the upstream lists them.
+func (s *store) crawledBetween(first, last time.Time) []comicshelf.Comic {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	// comic dates are calendar days at midnight UTC, whatever zone the week was worked out in
+	first = time.Date(first.Year(), first.Month(), first.Day(), 0, 0, 0, 0, time.UTC)
+	end := time.Date(last.Year(), last.Month(), last.Day()+1, 0, 0, 0, 0, time.UTC)
+	var comics []comicshelf.Comic
+	for id := range s.data.Crawled {
+		c, ok := s.data.Comics[id]
+		if ok && !c.OnSaleDate.Before(first) && c.OnSaleDate.Before(end) {
+			comics = append(comics, c)
+		}
+	}
+
+	sort.Slice(comics, func(i, j int) bool {
+		if comics[i].IssuerNumber != comics[j].IssuerNumber {
+			return comics[i].IssuerNumber < comics[j].IssuerNumber
+		}
+		return comics[i].Id < comics[j].Id
+	})
+
+	return comics
+}
+
+func (s *store) series(id int) (comicshelf.Series, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	series, ok := s.data.Series[id]
+	return series, ok
+}
+
+// comicsWithinSeries returns the listed comics of a series ordered by issue number, and whether that listing is
+// complete.
+func (s *store) comicsWithinSeries(id int) ([]comicshelf.Comic, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	ids := s.data.SeriesComics[id]
+	comics := make([]comicshelf.Comic, 0, len(ids))
+	for comicId := range ids {
+		if c, ok := s.data.Comics[comicId]; ok {
+			comics = append(comics, c)
+		}
+	}
+
+	sort.Slice(comics, func(i, j int) bool {
+		return comics[i].IssuerNumber < comics[j].IssuerNumber
+	})
+
+	return comics, s.data.Complete.Has(id)
+}
+
+func (s *store) allComics() []comicshelf.Comic {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	comics := make([]comicshelf.Comic, 0, len(s.data.Comics))
+	for _, c := range s.data.Comics {
+		comics = append(comics, c)
+	}
+	return comics
+}
+
+func (s *store) allSeries() []comicshelf.Series {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	series := make([]comicshelf.Series, 0, len(s.data.Series))
+	for _, c := range s.data.Series {
+		series = append(series, c)
+	}
+	return series
+}
+
+func (s *store) putComics(comics ...comicshelf.Comic) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	for _, c := range comics {
+		s.data.Comics[c.Id] = c
+		s.data.Fetched[c.Id] = now
+	}
+}
+
+// putCrawledComics stores comics listed by the crawl.
+func (s *store) putCrawledComics(comics ...comicshelf.Comic) {
+	s.putListedComics(comics...)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, c := range comics {
+		s.data.Crawled.Put(c.Id)
+	}
+}
+
+// putListedComics stores comics which came from a series style listing, recording their series membership.
+func (s *store) putListedComics(comics ...comicshelf.Comic) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	for _, c := range comics {
+		s.data.Comics[c.Id] = c
+		s.data.Fetched[c.Id] = now
+
+		listing, ok := s.data.SeriesComics[c.SeriesId]
+		if !ok {
+			listing = make(comicshelf.Set[int])
+			s.data.SeriesComics[c.SeriesId] = listing
+		}
+		listing.Put(c.Id)
+	}
+}
+
+func (s *store) putSeries(series ...comicshelf.Series) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, ser := range series {
+		ser.Comics = nil // comics are stored individually and rebuilt from the listing
+		s.data.Series[ser.Id] = ser
+	}
+}
+
+func (s *store) markComplete(seriesId int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.data.Complete.Put(seriesId)
+}
+
+func (s *store) cursor(name string) cursor {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.data.Cursors[name]
+}
+
+func (s *store) setCursor(name string, c cursor) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.data.Cursors[name] = c
+}
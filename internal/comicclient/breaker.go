@@ -0,0 +1,121 @@
+package comicclient
+
+import (
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+)
+
+var ErrCircuitOpen = errors.New("circuit open, upstream is failing")
+
+type BreakerConfig struct {
+	// Failures is how many requests in a row have to fail before the breaker opens, zero disables it.
+	Failures int `mapstructure:"failures"`
+	// Cooldown is how long the breaker stays open before letting a single request through to test the upstream.
+	Cooldown time.Duration `mapstructure:"cooldown"`
+}
+
+// Breaker stops requests reaching an upstream that keeps failing, giving it time to recover.
+// Transport errors, 429s and 5xxs count as failures, requests cancelled or timed out by their caller do not.
+type Breaker struct {
+	cfg *BreakerConfig
+	now func() time.Time
+
+	mu       sync.Mutex
+	failures int
+	openedAt time.Time // zero while closed
+	probing  bool
+}
+
+func NewBreaker(cfg *BreakerConfig) *Breaker {
+	return &Breaker{cfg: cfg, now: time.Now}
+}
+
+func (b *Breaker) Middleware() Middleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return MiddlewareFn(func(req *http.Request) (*http.Response, error) {
+			if !b.allow() {
+				return nil, ErrCircuitOpen
+			}
+
+			resp, err := next.RoundTrip(req)
+			if req.Context().Err() != nil {
+				// our own caller gave up, which says nothing about the upstream
+				b.abandon()
+				return resp, err
+			}
+
+			b.record(err != nil || resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= http.StatusInternalServerError)
+			return resp, err
+		})
+	}
+}
+
+// Open reports whether requests are currently being refused.
+func (b *Breaker) Open() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.open()
+}
+
+func (b *Breaker) open() bool {
+	if b.openedAt.IsZero() {
+		return false
+	}
+
+	return b.probing || b.now().Sub(b.openedAt) < b.cfg.Cooldown
+}
+
+func (b *Breaker) allow() bool {
+	if b.cfg.Failures <= 0 {
+		return true
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.open() {
+		return false
+	}
+
+	if !b.openedAt.IsZero() {
+		// cooled down, this request decides whether the breaker closes or opens again
+		b.probing = true
+	}
+
+	return true
+}
+
+func (b *Breaker) record(failed bool) {
+	if b.cfg.Failures <= 0 {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.probing = false
+	if !failed {
+		b.failures = 0
+		b.openedAt = time.Time{}
+		return
+	}
+
+	b.failures++
+	if b.failures >= b.cfg.Failures {
+		b.openedAt = b.now()
+	}
+}
+
+// abandon leaves the failure count alone, only letting another request probe if this one was.
+func (b *Breaker) abandon() {
+	if b.cfg.Failures <= 0 {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.probing = false
+}
@@ -0,0 +1,129 @@
+package comicclient
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeUpstream struct {
+	status int
+	calls  int
+}
+
+func (f *fakeUpstream) RoundTrip(req *http.Request) (*http.Response, error) {
+	f.calls++
+	return &http.Response{StatusCode: f.status}, nil
+}
+
+func newTestBreaker(t *testing.T) (*Breaker, *fakeUpstream, *time.Time, func() error) {
+	now := time.Date(2026, time.October, 19, 0, 0, 0, 0, time.UTC)
+	b := NewBreaker(&BreakerConfig{Failures: 2, Cooldown: time.Minute})
+	b.now = func() time.Time { return now }
+
+	upstream := &fakeUpstream{status: http.StatusInternalServerError}
+	transport := b.Middleware()(upstream)
+
+	req, err := http.NewRequest(http.MethodGet, "https://example.com", nil)
+	require.NoError(t, err)
+
+	do := func() error {
+		_, err := transport.RoundTrip(req)
+		return err
+	}
+
+	return b, upstream, &now, do
+}
+
+func TestBreakerOpensAfterFailures(t *testing.T) {
+	b, upstream, _, do := newTestBreaker(t)
+
+	require.NoError(t, do())
+	assert.False(t, b.Open())
+	require.NoError(t, do())
+	assert.True(t, b.Open())
+
+	assert.True(t, errors.Is(do(), ErrCircuitOpen))
+	assert.Equal(t, 2, upstream.calls)
+}
+
+func TestBreakerProbesAfterCooldown(t *testing.T) {
+	b, upstream, now, do := newTestBreaker(t)
+	_ = do()
+	_ = do()
+
+	*now = now.Add(time.Minute)
+	assert.False(t, b.Open())
+
+	// a failed probe opens the breaker for another cooldown
+	require.NoError(t, do())
+	assert.True(t, b.Open())
+	assert.True(t, errors.Is(do(), ErrCircuitOpen))
+
+	*now = now.Add(time.Minute)
+	upstream.status = http.StatusOK
+	require.NoError(t, do())
+	assert.False(t, b.Open())
+	require.NoError(t, do())
+	assert.Equal(t, 5, upstream.calls)
+}
+
+func TestBreakerSuccessResetsFailures(t *testing.T) {
+	b, upstream, _, do := newTestBreaker(t)
+
+	_ = do()
+	upstream.status = http.StatusNotModified
+	_ = do()
+	upstream.status = http.StatusBadGateway
+	_ = do()
+
+	assert.False(t, b.Open())
+}
+
+func TestBreakerIgnoresCancelledRequests(t *testing.T) {
+	b, upstream, now, do := newTestBreaker(t)
+	transport := b.Middleware()(upstream)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "https://example.com", nil)
+	require.NoError(t, err)
+
+	for i := 0; i < 3; i++ {
+		_, _ = transport.RoundTrip(req)
+	}
+	assert.False(t, b.Open())
+
+	_ = do()
+	_ = do()
+	require.True(t, b.Open())
+
+	// a cancelled probe hands probing on to the next request
+	*now = now.Add(time.Minute)
+	_, _ = transport.RoundTrip(req)
+	assert.False(t, b.Open())
+
+	upstream.status = http.StatusOK
+	require.NoError(t, do())
+	assert.False(t, b.Open())
+}
+
+func TestBreakerDisabled(t *testing.T) {
+	b := NewBreaker(&BreakerConfig{})
+	upstream := &fakeUpstream{status: http.StatusInternalServerError}
+	transport := b.Middleware()(upstream)
+
+	req, err := http.NewRequest(http.MethodGet, "https://example.com", nil)
+	require.NoError(t, err)
+	for i := 0; i < 5; i++ {
+		_, err := transport.RoundTrip(req)
+		require.NoError(t, err)
+	}
+
+	assert.False(t, b.Open())
+}
@@ -8,4 +8,5 @@ import (
 type Config struct {
 	Timeout time.Duration `mapstructure:"timeout"`
 	BaseURL *url.URL      `mapstructure:"base_url"`
+	Breaker BreakerConfig `mapstructure:"breaker"`
 }
@@ -42,7 +42,7 @@ func AddBaseMiddleware(url *url.URL) Middleware {
 				req.URL.Path = u.JoinPath(req.URL.Path).Path
 			}
 
-			slog.Debug("sending to", slog.String("url", req.URL.String()))
+			slog.DebugContext(req.Context(), "sending to", slog.String("url", req.URL.String()))
 			return next.RoundTrip(req)
 		})
 	}
@@ -2,4 +2,6 @@ package filedb
 
 type Config struct {
 	Filename string `mapstructure:"filename"`
+	// ReadOnly loads the database without taking its lock or ever saving it, so changes only last for the process.
+	ReadOnly bool `mapstructure:"-"`
 }
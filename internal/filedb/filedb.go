@@ -7,71 +7,89 @@ import (
 	"fmt"
 	"io"
 	"log/slog"
+	"maps"
 	"os"
+	"path/filepath"
 	"sync"
 	"time"
 
 	"github.com/jakedegiovanni/comicshelf"
+	"github.com/jakedegiovanni/comicshelf/internal/metrics"
 )
 
 var _ comicshelf.UserService = (*Db)(nil)
-
-var errUserNotFound = errors.New("user not found")
+var _ comicshelf.ReadingListService = (*Db)(nil)
 
 type Db struct {
-	file     *os.File
-	followed map[int]comicshelf.User
-	mu       *sync.RWMutex
-	quit     chan bool
+	filename   string
+	readOnly   bool
+	lock       *os.File
+	followed   map[int]comicshelf.User
+	lists      map[int]comicshelf.ReadingList
+	nextListId int
+	mu         *sync.RWMutex
+	quit       chan bool
+
+	flushMu   sync.Mutex
+	lastFlush error
 }
 
-func New(cfg *Config) (*Db, error) {
-	var f *os.File
-	var followed map[int]comicshelf.User
-
-	if _, err := os.Stat(cfg.Filename); err != nil {
-		if !errors.Is(err, os.ErrNotExist) {
-			return nil, err
-		}
-
-		f, err = os.Create(cfg.Filename)
+func New(cfg *Config) (db *Db, err error) {
+	var lockFile *os.File
+	if !cfg.ReadOnly {
+		lockFile, err = lock(cfg.Filename)
 		if err != nil {
 			return nil, err
 		}
+		defer func() {
+			if err != nil && lockFile != nil {
+				lockFile.Close()
+			}
+		}()
+	}
 
-		followed = make(map[int]comicshelf.User)
-		followed[0] = comicshelf.User{Id: 0, Following: make(comicshelf.Set[int])} // todo - onboarding process
-	} else {
-		b, err := os.ReadFile(cfg.Filename)
-		if err != nil {
-			return nil, err
-		}
+	var doc document
 
-		if len(b) > 0 {
-			err = json.Unmarshal(b, &followed)
-			if err != nil {
-				if !errors.Is(err, io.EOF) {
-					return nil, err
-				}
-				followed = make(map[int]comicshelf.User)
-				followed[0] = comicshelf.User{Id: 0, Following: make(comicshelf.Set[int])} // todo - onboarding process
-			}
-		} else {
-			followed = make(map[int]comicshelf.User)
-			followed[0] = comicshelf.User{Id: 0, Following: make(comicshelf.Set[int])} // todo - onboarding process
-		}
+	b, err := os.ReadFile(cfg.Filename)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
 
-		f, err = os.OpenFile(cfg.Filename, os.O_CREATE|os.O_TRUNC|os.O_RDWR, 0644)
-		if err != nil {
+	if len(b) > 0 {
+		doc, err = decode(b)
+		if err != nil && !errors.Is(err, io.EOF) {
 			return nil, err
 		}
 	}
 
-	db := &Db{
-		file:     f,
-		followed: followed,
-		mu:       new(sync.RWMutex),
-		quit:     make(chan bool),
+	if doc.Users == nil {
+		doc.Users = make(map[int]comicshelf.User)
+		doc.Users[0] = comicshelf.User{Id: 0, Following: make(comicshelf.Set[comicshelf.Follow])} // todo - onboarding process
+	}
+
+	if doc.Lists == nil {
+		doc.Lists = make(map[int]comicshelf.ReadingList)
+	}
+
+	db = &Db{
+		filename:   cfg.Filename,
+		readOnly:   cfg.ReadOnly,
+		lock:       lockFile,
+		followed:   doc.Users,
+		lists:      doc.Lists,
+		nextListId: doc.NextListId,
+		mu:         new(sync.RWMutex),
+		quit:       make(chan bool),
+	}
+
+	if db.readOnly {
+		return db, nil
+	}
+
+	// written straight away so a file that cannot be saved stops startup rather than the first flush
+	err = db.write()
+	if err != nil {
+		return nil, err
 	}
 
 	db.timedFlush()
@@ -86,32 +104,124 @@ func (d *Db) timedFlush() {
 			case <-d.quit:
 				return
 			case <-timer.C:
-				d.flush()
+				_ = d.flush() // logged by flush, surfaced through CheckFlushed
 			}
 		}
 	}()
 }
 
-func (d *Db) flush() {
-	_ = d.file.Truncate(0)
-	_, _ = d.file.Seek(0, io.SeekStart)
+func (d *Db) flush() error {
+	d.flushMu.Lock()
+	defer d.flushMu.Unlock()
+
+	start := time.Now()
+	err := d.write()
+	metrics.FlushDuration.Observe(time.Since(start).Seconds())
 
-	err := json.NewEncoder(d.file).Encode(d.followed)
+	d.lastFlush = err
 	if err != nil {
+		metrics.FlushFailures.Inc()
 		slog.Error("db save error", slog.String("err", err.Error()))
-		return
+		return err
 	}
+
 	slog.Debug("db saved")
+	return nil
 }
 
-func (d *Db) Shutdown() {
+// write saves the database aside and renames it into place, so a write cut short leaves the last save intact.
+func (d *Db) write() error {
+	d.mu.RLock()
+	b, err := json.Marshal(document{
+		Version:    version,
+		Users:      d.followed,
+		Lists:      d.lists,
+		NextListId: d.nextListId,
+	})
+	d.mu.RUnlock()
+	if err != nil {
+		return err
+	}
+
+	f, err := os.CreateTemp(filepath.Dir(d.filename), filepath.Base(d.filename)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name()) // a no-op once renamed
+
+	err = f.Chmod(0644)
+	if err == nil {
+		_, err = f.Write(b)
+	}
+	if err == nil {
+		err = f.Sync()
+	}
+	if err != nil {
+		f.Close()
+		return err
+	}
+
+	err = f.Close()
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(f.Name(), d.filename)
+}
+
+// CheckWritable reports whether new files can be written next to the database.
+func (d *Db) CheckWritable(ctx context.Context) error {
+	f, err := os.CreateTemp(filepath.Dir(d.filename), ".filedb-check-*")
+	if err != nil {
+		return err
+	}
+
+	f.Close()
+	return os.Remove(f.Name())
+}
+
+// CheckFlushed reports the error from the most recent write to disk, if it failed.
+func (d *Db) CheckFlushed(ctx context.Context) error {
+	d.flushMu.Lock()
+	defer d.flushMu.Unlock()
+
+	if d.lastFlush != nil {
+		return fmt.Errorf("last flush failed: %w", d.lastFlush)
+	}
+
+	return nil
+}
+
+// Shutdown stops the timed flush and makes a final write to disk, reporting anything that stopped it landing.
+// Giving up on ctx leaves the last completed save in place, a write is never left half done.
+// A read only database is left untouched.
+func (d *Db) Shutdown(ctx context.Context) error {
 	slog.Debug("shutting down db")
-	defer d.file.Close()
 	close(d.quit)
-	d.flush()
+
+	if d.readOnly {
+		return nil
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		err := d.flush()
+		// held until the last write lands so another process cannot load what is about to be replaced
+		if d.lock != nil {
+			err = errors.Join(err, d.lock.Close())
+		}
+		done <- err
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return fmt.Errorf("final flush did not finish: %w", ctx.Err())
+	}
 }
 
-func (d *Db) Following(ctx context.Context, userId, seriesId int) (bool, error) {
+func (d *Db) Following(ctx context.Context, userId int, follow comicshelf.Follow) (bool, error) {
 	d.mu.RLock()
 	defer d.mu.RUnlock()
 
@@ -120,22 +230,23 @@ func (d *Db) Following(ctx context.Context, userId, seriesId int) (bool, error)
 		return false, err
 	}
 
-	return user.Following.Has(seriesId), nil
+	return user.Following.Has(follow), nil
 }
 
-func (d *Db) Followed(ctx context.Context, userId int) (comicshelf.Set[int], error) {
+func (d *Db) Followed(ctx context.Context, userId int) (comicshelf.Set[comicshelf.Follow], error) {
 	d.mu.RLock()
 	defer d.mu.RUnlock()
 
 	user, err := d.getUser(userId)
 	if err != nil {
-		return comicshelf.Set[int]{}, err
+		return comicshelf.Set[comicshelf.Follow]{}, err
 	}
 
-	return user.Following, nil
+	// a copy, callers range over it while follows change underneath
+	return maps.Clone(user.Following), nil
 }
 
-func (d *Db) Follow(ctx context.Context, userId, seriesId int) error {
+func (d *Db) Follow(ctx context.Context, userId int, follow comicshelf.Follow) error {
 	d.mu.Lock()
 	defer d.mu.Unlock()
 
@@ -144,14 +255,18 @@ func (d *Db) Follow(ctx context.Context, userId, seriesId int) error {
 		return err
 	}
 
-	user.Following.Put(seriesId)
+	if user.Following == nil {
+		user.Following = make(comicshelf.Set[comicshelf.Follow])
+	}
+
+	user.Following.Put(follow)
 	d.followed[userId] = user
 
 	slog.Debug(fmt.Sprintf("%+v", d.followed))
 	return nil
 }
 
-func (d *Db) Unfollow(ctx context.Context, userId, seriesId int) error {
+func (d *Db) Unfollow(ctx context.Context, userId int, follow comicshelf.Follow) error {
 	d.mu.Lock()
 	defer d.mu.Unlock()
 
@@ -160,15 +275,109 @@ func (d *Db) Unfollow(ctx context.Context, userId, seriesId int) error {
 		return err
 	}
 
-	user.Following.Delete(seriesId)
+	user.Following.Delete(follow)
 	d.followed[userId] = user
 	return nil
 }
 
+func (d *Db) IssueStates(ctx context.Context, userId int) (map[int]comicshelf.IssueStates, error) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	user, err := d.getUser(userId)
+	if err != nil {
+		return nil, err
+	}
+
+	issues := make(map[int]comicshelf.IssueStates, len(user.Issues))
+	for comicId, states := range user.Issues {
+		issues[comicId] = maps.Clone(states)
+	}
+
+	return issues, nil
+}
+
+func (d *Db) SetIssueState(ctx context.Context, userId, comicId int, state comicshelf.IssueState, at time.Time) (comicshelf.IssueStates, error) {
+	return d.updateIssueStates(userId, comicId, func(states comicshelf.IssueStates) {
+		// setting a state again, say from a re-run import, keeps when it was first set
+		if prev, ok := states[state]; ok && !at.Before(prev) {
+			return
+		}
+
+		states[state] = at.UTC()
+	})
+}
+
+func (d *Db) ClearIssueState(ctx context.Context, userId, comicId int, state comicshelf.IssueState) (comicshelf.IssueStates, error) {
+	return d.updateIssueStates(userId, comicId, func(states comicshelf.IssueStates) {
+		delete(states, state)
+	})
+}
+
+func (d *Db) updateIssueStates(userId, comicId int, update func(comicshelf.IssueStates)) (comicshelf.IssueStates, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	user, err := d.getUser(userId)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.Issues == nil {
+		user.Issues = make(map[int]comicshelf.IssueStates)
+	}
+
+	states, ok := user.Issues[comicId]
+	if !ok {
+		states = make(comicshelf.IssueStates)
+	}
+
+	update(states)
+
+	if len(states) == 0 {
+		delete(user.Issues, comicId)
+	} else {
+		user.Issues[comicId] = states
+	}
+	d.followed[userId] = user
+
+	return maps.Clone(states), nil
+}
+
+func (d *Db) NotificationsSeen(ctx context.Context, userId int) (time.Time, error) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	user, err := d.getUser(userId)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	return user.NotificationsSeen, nil
+}
+
+func (d *Db) SeeNotifications(ctx context.Context, userId int, at time.Time) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	user, err := d.getUser(userId)
+	if err != nil {
+		return err
+	}
+
+	// a page rendered before a later one must not move it back
+	if at.After(user.NotificationsSeen) {
+		user.NotificationsSeen = at
+		d.followed[userId] = user
+	}
+
+	return nil
+}
+
 func (d *Db) getUser(userId int) (comicshelf.User, error) {
 	user, ok := d.followed[userId]
 	if !ok {
-		return comicshelf.User{}, fmt.Errorf("no user with id: %d - %w", userId, errUserNotFound)
+		return comicshelf.User{}, fmt.Errorf("no user with id: %d - %w", userId, comicshelf.ErrNotFound)
 	}
 
 	return user, nil
@@ -0,0 +1,30 @@
+//go:build unix
+
+package filedb
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"syscall"
+)
+
+// lock takes an exclusive lock beside the database so a second process, such as an import while the server is
+// running, cannot load it and later overwrite the other's changes. The kernel drops the lock if the process dies.
+func lock(filename string) (*os.File, error) {
+	f, err := os.OpenFile(filename+".lock", os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, err
+	}
+
+	err = syscall.Flock(int(f.Fd()), syscall.LOCK_EX|syscall.LOCK_NB)
+	if err != nil {
+		f.Close()
+		if errors.Is(err, syscall.EWOULDBLOCK) {
+			return nil, fmt.Errorf("%s is in use by another process, stop the server first", filename)
+		}
+		return nil, err
+	}
+
+	return f, nil
+}
@@ -0,0 +1,62 @@
+package filedb
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/jakedegiovanni/comicshelf"
+)
+
+// version is the current on disk format of the db file.
+//   - 0: bare map of user id to user, following holds series ids only
+//   - 1: versioned document, following holds typed follows
+const version = 1
+
+type document struct {
+	Version    int                            `json:"version"`
+	Users      map[int]comicshelf.User        `json:"users"`
+	Lists      map[int]comicshelf.ReadingList `json:"lists"`
+	NextListId int                            `json:"next_list_id"`
+}
+
+type userV0 struct {
+	Id        int                 `json:"id"`
+	Following comicshelf.Set[int] `json:"following"`
+}
+
+func decode(b []byte) (document, error) {
+	var doc document
+	err := json.Unmarshal(b, &doc)
+	if err != nil {
+		return document{}, err
+	}
+
+	switch doc.Version {
+	case 0:
+		return migrateV0(b)
+	case version:
+		return doc, nil
+	default:
+		return document{}, fmt.Errorf("unsupported db version: %d", doc.Version)
+	}
+}
+
+func migrateV0(b []byte) (document, error) {
+	var old map[int]userV0
+	err := json.Unmarshal(b, &old)
+	if err != nil {
+		return document{}, fmt.Errorf("could not decode version 0 db: %w", err)
+	}
+
+	users := make(map[int]comicshelf.User, len(old))
+	for id, u := range old {
+		following := make(comicshelf.Set[comicshelf.Follow], len(u.Following))
+		for seriesId := range u.Following {
+			following.Put(comicshelf.Follow{Kind: comicshelf.FollowSeries, Id: seriesId})
+		}
+
+		users[id] = comicshelf.User{Id: u.Id, Following: following}
+	}
+
+	return document{Version: version, Users: users}, nil
+}
@@ -0,0 +1,95 @@
+package filedb
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jakedegiovanni/comicshelf"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecodeMigratesV0(t *testing.T) {
+	doc, err := decode([]byte(`{"0":{"id":0,"following":{"123":{},"456":{}}}}`))
+	require.Nil(t, err)
+
+	require.Contains(t, doc.Users, 0)
+	assert.Equal(t, comicshelf.Set[comicshelf.Follow]{
+		{Kind: comicshelf.FollowSeries, Id: 123}: {},
+		{Kind: comicshelf.FollowSeries, Id: 456}: {},
+	}, doc.Users[0].Following)
+}
+
+func TestDecodeCurrentVersion(t *testing.T) {
+	doc, err := decode([]byte(`{"version":1,"users":{"0":{"id":0,"following":{"series:1":{},"creator:2":{},"character:3":{}}}}}`))
+	require.Nil(t, err)
+
+	require.Contains(t, doc.Users, 0)
+	assert.Equal(t, comicshelf.Set[comicshelf.Follow]{
+		{Kind: comicshelf.FollowSeries, Id: 1}:    {},
+		{Kind: comicshelf.FollowCreator, Id: 2}:   {},
+		{Kind: comicshelf.FollowCharacter, Id: 3}: {},
+	}, doc.Users[0].Following)
+}
+
+func TestDecodeUnknownVersion(t *testing.T) {
+	_, err := decode([]byte(`{"version":99,"users":{}}`))
+	assert.NotNil(t, err)
+}
+
+func TestFollowsSurviveMigration(t *testing.T) {
+	tests := []struct {
+		name string
+		file string
+		want comicshelf.Set[comicshelf.Follow]
+	}{
+		{
+			name: "version 0",
+			file: `{"0":{"id":0,"following":{"123":{}}}}`,
+			want: comicshelf.Set[comicshelf.Follow]{{Kind: comicshelf.FollowSeries, Id: 123}: {}},
+		},
+		{
+			name: "version 0 following nothing",
+			file: `{"0":{"id":0,"following":{}}}`,
+			want: comicshelf.Set[comicshelf.Follow]{},
+		},
+		{
+			name: "current version",
+			file: `{"version":1,"users":{"0":{"id":0,"following":{"series:1":{},"creator:2":{},"character:3":{}}}}}`,
+			want: comicshelf.Set[comicshelf.Follow]{
+				{Kind: comicshelf.FollowSeries, Id: 1}:    {},
+				{Kind: comicshelf.FollowCreator, Id: 2}:   {},
+				{Kind: comicshelf.FollowCharacter, Id: 3}: {},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filename := filepath.Join(t.TempDir(), "db.json")
+			require.Nil(t, os.WriteFile(filename, []byte(tt.file), 0644))
+
+			// loaded, saved in the current format and loaded again
+			for i := 0; i < 2; i++ {
+				db, err := New(&Config{Filename: filename})
+				require.Nil(t, err)
+
+				followed, err := db.Followed(context.Background(), 0)
+				require.Nil(t, err)
+				assert.Equal(t, tt.want, followed)
+				require.Nil(t, db.Shutdown(context.Background()))
+			}
+
+			b, err := os.ReadFile(filename)
+			require.Nil(t, err)
+			var saved struct {
+				Version int `json:"version"`
+			}
+			require.Nil(t, json.Unmarshal(b, &saved))
+			assert.Equal(t, version, saved.Version)
+		})
+	}
+}
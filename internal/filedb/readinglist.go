@@ -0,0 +1,178 @@
+package filedb
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/jakedegiovanni/comicshelf"
+)
+
+func (d *Db) ReadingLists(ctx context.Context, userId int) ([]comicshelf.ReadingList, error) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	_, err := d.getUser(userId)
+	if err != nil {
+		return nil, err
+	}
+
+	var lists []comicshelf.ReadingList
+	for _, list := range d.lists {
+		if list.OwnerId == userId {
+			lists = append(lists, cloneList(list))
+		}
+	}
+
+	sort.Slice(lists, func(i, j int) bool {
+		return lists[i].Id < lists[j].Id
+	})
+
+	return lists, nil
+}
+
+func (d *Db) ReadingList(ctx context.Context, userId, listId int) (comicshelf.ReadingList, error) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	list, ok := d.lists[listId]
+	if !ok || (list.OwnerId != userId && !list.Shared) {
+		return comicshelf.ReadingList{}, fmt.Errorf("no reading list with id: %d - %w", listId, comicshelf.ErrNotFound)
+	}
+
+	return cloneList(list), nil
+}
+
+func (d *Db) CreateReadingList(ctx context.Context, userId int, list comicshelf.ReadingList) (comicshelf.ReadingList, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	_, err := d.getUser(userId)
+	if err != nil {
+		return comicshelf.ReadingList{}, err
+	}
+
+	d.nextListId++
+	now := time.Now().UTC()
+
+	list = cloneList(list)
+	list.Id = d.nextListId
+	list.OwnerId = userId
+	list.Created = now
+	list.Updated = now
+	if list.Comics == nil {
+		list.Comics = []int{}
+	}
+
+	d.lists[list.Id] = list
+	return cloneList(list), nil
+}
+
+func (d *Db) UpdateReadingList(ctx context.Context, userId int, list comicshelf.ReadingList) (comicshelf.ReadingList, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	existing, err := d.ownedList(userId, list.Id)
+	if err != nil {
+		return comicshelf.ReadingList{}, err
+	}
+
+	existing.Name = list.Name
+	existing.Description = list.Description
+	existing.Shared = list.Shared
+	existing.Updated = time.Now().UTC()
+
+	d.lists[existing.Id] = existing
+	return cloneList(existing), nil
+}
+
+func (d *Db) DeleteReadingList(ctx context.Context, userId, listId int) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	_, err := d.ownedList(userId, listId)
+	if err != nil {
+		return err
+	}
+
+	delete(d.lists, listId)
+	return nil
+}
+
+func (d *Db) AddToReadingList(ctx context.Context, userId, listId, comicId int) (comicshelf.ReadingList, error) {
+	return d.updateListComics(userId, listId, func(comics []int) ([]int, error) {
+		if slices.Contains(comics, comicId) {
+			return comics, nil
+		}
+
+		return append(comics, comicId), nil
+	})
+}
+
+func (d *Db) RemoveFromReadingList(ctx context.Context, userId, listId, comicId int) (comicshelf.ReadingList, error) {
+	return d.updateListComics(userId, listId, func(comics []int) ([]int, error) {
+		return slices.DeleteFunc(comics, func(id int) bool {
+			return id == comicId
+		}), nil
+	})
+}
+
+func (d *Db) ReorderReadingList(ctx context.Context, userId, listId int, order []int) (comicshelf.ReadingList, error) {
+	return d.updateListComics(userId, listId, func(comics []int) ([]int, error) {
+		// anything but a permutation of the list means the order was made from a stale copy
+		if !samePermutation(order, comics) {
+			return nil, fmt.Errorf("order does not match the comics on reading list: %d - %w", listId, comicshelf.ErrConflict)
+		}
+
+		return slices.Clone(order), nil
+	})
+}
+
+// updateListComics applies update to the comics of a list the user owns, holding the lock between reading and writing them.
+func (d *Db) updateListComics(userId, listId int, update func([]int) ([]int, error)) (comicshelf.ReadingList, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	list, err := d.ownedList(userId, listId)
+	if err != nil {
+		return comicshelf.ReadingList{}, err
+	}
+
+	comics, err := update(slices.Clone(list.Comics))
+	if err != nil {
+		return comicshelf.ReadingList{}, err
+	}
+
+	list.Comics = comics
+	list.Updated = time.Now().UTC()
+
+	d.lists[listId] = list
+	return cloneList(list), nil
+}
+
+func samePermutation(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	a, b = slices.Clone(a), slices.Clone(b)
+	slices.Sort(a)
+	slices.Sort(b)
+	return slices.Equal(a, b)
+}
+
+func (d *Db) ownedList(userId, listId int) (comicshelf.ReadingList, error) {
+	list, ok := d.lists[listId]
+	if !ok || list.OwnerId != userId {
+		return comicshelf.ReadingList{}, fmt.Errorf("no reading list with id: %d for user: %d - %w", listId, userId, comicshelf.ErrNotFound)
+	}
+
+	return list, nil
+}
+
+func cloneList(list comicshelf.ReadingList) comicshelf.ReadingList {
+	list.Comics = slices.Clone(list.Comics)
+	return list
+}
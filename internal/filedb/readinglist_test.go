@@ -0,0 +1,97 @@
+package filedb
+
+import (
+	"context"
+	"path/filepath"
+	"slices"
+	"sync"
+	"testing"
+
+	"github.com/jakedegiovanni/comicshelf"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadingListSharing(t *testing.T) {
+	db, err := New(&Config{Filename: filepath.Join(t.TempDir(), "db.json")})
+	require.Nil(t, err)
+	t.Cleanup(func() {
+		require.NoError(t, db.Shutdown(context.Background()))
+	})
+
+	ctx := context.Background()
+	db.followed[1] = comicshelf.User{Id: 1, Following: make(comicshelf.Set[comicshelf.Follow])}
+
+	list, err := db.CreateReadingList(ctx, 0, comicshelf.ReadingList{Name: "Secret Wars", Comics: []int{3, 1, 2}})
+	require.Nil(t, err)
+
+	_, err = db.ReadingList(ctx, 1, list.Id)
+	assert.ErrorIs(t, err, comicshelf.ErrNotFound)
+
+	list.Shared = true
+	_, err = db.UpdateReadingList(ctx, 0, list)
+	require.Nil(t, err)
+
+	shared, err := db.ReadingList(ctx, 1, list.Id)
+	require.Nil(t, err)
+	assert.Equal(t, []int{3, 1, 2}, shared.Comics)
+
+	_, err = db.UpdateReadingList(ctx, 1, shared)
+	assert.ErrorIs(t, err, comicshelf.ErrNotFound)
+
+	assert.ErrorIs(t, db.DeleteReadingList(ctx, 1, list.Id), comicshelf.ErrNotFound)
+	assert.Nil(t, db.DeleteReadingList(ctx, 0, list.Id))
+}
+
+func TestReadingListComics(t *testing.T) {
+	db, err := New(&Config{Filename: filepath.Join(t.TempDir(), "db.json")})
+	require.Nil(t, err)
+	t.Cleanup(func() {
+		require.NoError(t, db.Shutdown(context.Background()))
+	})
+
+	ctx := context.Background()
+	list, err := db.CreateReadingList(ctx, 0, comicshelf.ReadingList{Name: "Secret Wars", Comics: []int{1}})
+	require.Nil(t, err)
+
+	// every add lands even when they race, none is lost to another's stale copy
+	var wg sync.WaitGroup
+	for i := 2; i <= 50; i++ {
+		wg.Add(1)
+		go func(id int) {
+			defer wg.Done()
+			_, err := db.AddToReadingList(ctx, 0, list.Id, id)
+			assert.Nil(t, err)
+		}(i)
+	}
+	wg.Wait()
+
+	list, err = db.AddToReadingList(ctx, 0, list.Id, 1)
+	require.Nil(t, err)
+	assert.Len(t, list.Comics, 50)
+
+	list, err = db.RemoveFromReadingList(ctx, 0, list.Id, 1)
+	require.Nil(t, err)
+	assert.NotContains(t, list.Comics, 1)
+
+	_, err = db.ReorderReadingList(ctx, 0, list.Id, []int{2, 3})
+	assert.ErrorIs(t, err, comicshelf.ErrConflict)
+
+	order := slices.Clone(list.Comics)
+	slices.Reverse(order)
+	list, err = db.ReorderReadingList(ctx, 0, list.Id, order)
+	require.Nil(t, err)
+	assert.Equal(t, order, list.Comics)
+
+	// settings saved from a page loaded before the reorder leave the comics alone
+	stale := list
+	stale.Comics = []int{2}
+	stale.Name = "Secret Wars (2015)"
+	list, err = db.UpdateReadingList(ctx, 0, stale)
+	require.Nil(t, err)
+	assert.Equal(t, "Secret Wars (2015)", list.Name)
+	assert.Equal(t, order, list.Comics)
+
+	_, err = db.AddToReadingList(ctx, 1, list.Id, 99)
+	assert.ErrorIs(t, err, comicshelf.ErrNotFound)
+}
@@ -0,0 +1,163 @@
+package filedb
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jakedegiovanni/comicshelf"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFollowedIsACopy(t *testing.T) {
+	db, err := New(&Config{Filename: filepath.Join(t.TempDir(), "db.json")})
+	require.Nil(t, err)
+	t.Cleanup(func() {
+		require.NoError(t, db.Shutdown(context.Background()))
+	})
+
+	ctx := context.Background()
+	follow := comicshelf.Follow{Kind: comicshelf.FollowSeries, Id: 1}
+
+	followed, err := db.Followed(ctx, 0)
+	require.Nil(t, err)
+
+	require.Nil(t, db.Follow(ctx, 0, follow))
+	assert.False(t, followed.Has(follow))
+
+	followed, err = db.Followed(ctx, 0)
+	require.Nil(t, err)
+	followed.Delete(follow)
+
+	following, err := db.Following(ctx, 0, follow)
+	require.Nil(t, err)
+	assert.True(t, following)
+}
+
+func TestSeeNotificationsKeepsLatest(t *testing.T) {
+	db, err := New(&Config{Filename: filepath.Join(t.TempDir(), "db.json")})
+	require.Nil(t, err)
+	t.Cleanup(func() {
+		require.NoError(t, db.Shutdown(context.Background()))
+	})
+
+	ctx := context.Background()
+	later := time.Date(2023, time.August, 2, 12, 0, 0, 0, time.UTC)
+
+	require.Nil(t, db.SeeNotifications(ctx, 0, later))
+	require.Nil(t, db.SeeNotifications(ctx, 0, later.AddDate(0, 0, -1)))
+
+	seen, err := db.NotificationsSeen(ctx, 0)
+	require.Nil(t, err)
+	assert.Equal(t, later, seen)
+}
+
+func TestSetIssueStateKeepsEarliest(t *testing.T) {
+	db, err := New(&Config{Filename: filepath.Join(t.TempDir(), "db.json")})
+	require.Nil(t, err)
+	t.Cleanup(func() {
+		require.NoError(t, db.Shutdown(context.Background()))
+	})
+
+	ctx := context.Background()
+	first := time.Date(2023, time.August, 2, 12, 0, 0, 0, time.UTC)
+
+	_, err = db.SetIssueState(ctx, 0, 1, comicshelf.StateRead, first)
+	require.Nil(t, err)
+
+	states, err := db.SetIssueState(ctx, 0, 1, comicshelf.StateRead, first.AddDate(0, 0, 1))
+	require.Nil(t, err)
+	assert.Equal(t, first, states[comicshelf.StateRead])
+
+	states, err = db.SetIssueState(ctx, 0, 1, comicshelf.StateRead, first.AddDate(0, 0, -1))
+	require.Nil(t, err)
+	assert.Equal(t, first.AddDate(0, 0, -1), states[comicshelf.StateRead])
+}
+
+func TestFollowWithoutFollowingSet(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "db.json")
+	require.Nil(t, os.WriteFile(filename, []byte(`{"version": 1, "users": {"0": {"id": 0}}}`), 0644))
+
+	db, err := New(&Config{Filename: filename})
+	require.Nil(t, err)
+	t.Cleanup(func() {
+		require.NoError(t, db.Shutdown(context.Background()))
+	})
+
+	ctx := context.Background()
+	follow := comicshelf.Follow{Kind: comicshelf.FollowSeries, Id: 1}
+	require.Nil(t, db.Unfollow(ctx, 0, follow))
+	require.Nil(t, db.Follow(ctx, 0, follow))
+
+	following, err := db.Following(ctx, 0, follow)
+	require.Nil(t, err)
+	assert.True(t, following)
+}
+
+func TestShutdownSavesInPlace(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "db.json")
+
+	db, err := New(&Config{Filename: filename})
+	require.Nil(t, err)
+
+	ctx := context.Background()
+	follow := comicshelf.Follow{Kind: comicshelf.FollowSeries, Id: 1}
+	require.Nil(t, db.Follow(ctx, 0, follow))
+	require.Nil(t, db.Shutdown(ctx))
+
+	entries, err := os.ReadDir(dir)
+	require.Nil(t, err)
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	assert.Equal(t, []string{"db.json", "db.json.lock"}, names, "no temporary files are left behind")
+
+	db, err = New(&Config{Filename: filename})
+	require.Nil(t, err)
+	t.Cleanup(func() {
+		require.NoError(t, db.Shutdown(context.Background()))
+	})
+
+	following, err := db.Following(ctx, 0, follow)
+	require.Nil(t, err)
+	assert.True(t, following)
+}
+
+func TestOpenedByOneProcessAtATime(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "db.json")
+
+	db, err := New(&Config{Filename: filename})
+	require.Nil(t, err)
+
+	_, err = New(&Config{Filename: filename})
+	assert.ErrorContains(t, err, "in use by another process")
+
+	readOnly, err := New(&Config{Filename: filename, ReadOnly: true})
+	require.Nil(t, err, "a read only copy does not need the lock")
+	require.Nil(t, readOnly.Shutdown(context.Background()))
+
+	require.Nil(t, db.Shutdown(context.Background()))
+
+	db, err = New(&Config{Filename: filename})
+	require.Nil(t, err, "the lock is released on shutdown")
+	require.Nil(t, db.Shutdown(context.Background()))
+}
+
+func TestReadOnlyNeverSaves(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "db.json")
+
+	db, err := New(&Config{Filename: filename, ReadOnly: true})
+	require.Nil(t, err)
+	require.Nil(t, db.Follow(context.Background(), 0, comicshelf.Follow{Kind: comicshelf.FollowSeries, Id: 1}))
+	require.Nil(t, db.Shutdown(context.Background()))
+
+	entries, err := os.ReadDir(dir)
+	require.Nil(t, err)
+	assert.Empty(t, entries)
+}
@@ -0,0 +1,173 @@
+package importer
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jakedegiovanni/comicshelf"
+)
+
+type Format string
+
+const (
+	// FormatCSV is comicshelf's own layout, a header of series,issue,read_date where a blank read date means unread.
+	FormatCSV Format = "csv"
+	// FormatCLZ is the CSV export of CLZ Comics, using its Series, Issue Nr, Read It and Read Date columns.
+	FormatCLZ Format = "clz"
+)
+
+var dateLayouts = []string{
+	"2006-01-02",
+	"01/02/2006",
+	"Jan 2, 2006",
+	"2 Jan 2006",
+}
+
+// Row is a single issue from an import file.
+type Row struct {
+	Line   int       `json:"line"`
+	Series string    `json:"series"`
+	Issue  string    `json:"issue"`
+	Read   bool      `json:"read"`
+	ReadAt time.Time `json:"read_at"`
+}
+
+type columns struct {
+	series   []string
+	issue    []string
+	readDate []string
+	readFlag []string
+}
+
+func Parse(format Format, r io.Reader) ([]Row, error) {
+	switch format {
+	case FormatCSV:
+		return parse(r, columns{
+			series:   []string{"series"},
+			issue:    []string{"issue"},
+			readDate: []string{"read_date"},
+		})
+	case FormatCLZ:
+		return parse(r, columns{
+			series:   []string{"series"},
+			issue:    []string{"issue nr", "issue"},
+			readDate: []string{"read date"},
+			readFlag: []string{"read it", "read"},
+		})
+	default:
+		return nil, fmt.Errorf("unknown import format: %s - %w", format, comicshelf.ErrInvalidInput)
+	}
+}
+
+func parse(r io.Reader, cols columns) ([]Row, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("could not read header: %w - %w", err, comicshelf.ErrInvalidInput)
+	}
+
+	// spreadsheet exports commonly start with a byte order mark
+	index := make(map[string]int, len(header))
+	for i, h := range header {
+		index[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(h, "\ufeff")))] = i
+	}
+
+	series, ok := lookup(index, cols.series)
+	if !ok {
+		return nil, fmt.Errorf("missing series column - %w", comicshelf.ErrInvalidInput)
+	}
+
+	issue, ok := lookup(index, cols.issue)
+	if !ok {
+		return nil, fmt.Errorf("missing issue column - %w", comicshelf.ErrInvalidInput)
+	}
+
+	readDate, hasReadDate := lookup(index, cols.readDate)
+	readFlag, hasReadFlag := lookup(index, cols.readFlag)
+
+	var rows []Row
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("could not read line %d: %w - %w", line, err, comicshelf.ErrInvalidInput)
+		}
+
+		row := Row{
+			Line:   line,
+			Series: field(record, series),
+			Issue:  strings.TrimPrefix(field(record, issue), "#"),
+		}
+
+		if hasReadDate && field(record, readDate) != "" {
+			row.ReadAt, err = parseDate(field(record, readDate))
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", line, err)
+			}
+			row.Read = true
+		}
+
+		if hasReadFlag {
+			read, err := parseFlag(field(record, readFlag))
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", line, err)
+			}
+			row.Read = row.Read || read
+		}
+
+		rows = append(rows, row)
+	}
+
+	return rows, nil
+}
+
+func lookup(index map[string]int, names []string) (int, bool) {
+	for _, name := range names {
+		if i, ok := index[name]; ok {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+func field(record []string, i int) string {
+	if i >= len(record) {
+		return ""
+	}
+	return strings.TrimSpace(record[i])
+}
+
+func parseDate(s string) (time.Time, error) {
+	for _, layout := range dateLayouts {
+		t, err := time.Parse(layout, s)
+		if err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("unrecognised date: %s - %w", s, comicshelf.ErrInvalidInput)
+}
+
+func parseFlag(s string) (bool, error) {
+	switch strings.ToLower(s) {
+	case "yes", "y":
+		return true, nil
+	case "no", "n", "":
+		return false, nil
+	}
+
+	b, err := strconv.ParseBool(s)
+	if err != nil {
+		return false, fmt.Errorf("unrecognised read flag: %s - %w", s, comicshelf.ErrInvalidInput)
+	}
+	return b, nil
+}
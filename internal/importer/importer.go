@@ -0,0 +1,240 @@
+package importer
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jakedegiovanni/comicshelf"
+)
+
+const (
+	// minSimilarity is how alike a marvel series title must be to the imported title to be considered at all.
+	minSimilarity = 0.8
+	// ambiguityMargin is how close the runner up must be to the best candidate for the match to be ambiguous.
+	ambiguityMargin = 0.05
+)
+
+var yearPattern = regexp.MustCompile(`\((\d{4})[^)]*\)`)
+
+// Finder looks up series and their comics, the marvel client satisfies it.
+type Finder interface {
+	SearchSeries(ctx context.Context, prefix string) ([]comicshelf.Series, error)
+	GetComicsWithinSeries(ctx context.Context, id int) ([]comicshelf.Comic, error)
+}
+
+// Scorer rates how alike two series titles are from 0 to 1.
+type Scorer interface {
+	Similarity(a, b string) float64
+}
+
+// ScorerFunc lets a plain function be used as a Scorer.
+type ScorerFunc func(a, b string) float64
+
+func (f ScorerFunc) Similarity(a, b string) float64 {
+	return f(a, b)
+}
+
+type Match struct {
+	Row     Row `json:"row"`
+	Series  int `json:"series_id"`
+	ComicId int `json:"comic_id"`
+}
+
+type Ambiguous struct {
+	Row        Row                 `json:"row"`
+	Candidates []comicshelf.Series `json:"candidates"`
+}
+
+type Unmatched struct {
+	Row    Row    `json:"row"`
+	Reason string `json:"reason"`
+}
+
+type Report struct {
+	Matched   []Match     `json:"matched"`
+	Ambiguous []Ambiguous `json:"ambiguous"`
+	Unmatched []Unmatched `json:"unmatched"`
+}
+
+type Importer struct {
+	finder Finder
+	scorer Scorer
+	users  comicshelf.UserService
+	userId int
+	dryRun bool
+}
+
+func New(finder Finder, scorer Scorer, users comicshelf.UserService, userId int, dryRun bool) *Importer {
+	return &Importer{
+		finder: finder,
+		scorer: scorer,
+		users:  users,
+		userId: userId,
+		dryRun: dryRun,
+	}
+}
+
+type seriesMatch struct {
+	candidates []comicshelf.Series
+	comics     []comicshelf.Comic
+	reason     string
+}
+
+// Import matches every row to a marvel comic. Matched rows follow their series and, when read, mark the comic as read
+// at the imported date. Rows which are ambiguous or cannot be matched are only reported.
+func (i *Importer) Import(ctx context.Context, rows []Row) (Report, error) {
+	var report Report
+	matches := make(map[string]*seriesMatch)
+
+	for _, row := range rows {
+		m, ok := matches[row.Series]
+		if !ok {
+			var err error
+			m, err = i.matchSeries(ctx, row.Series)
+			if err != nil {
+				return Report{}, err
+			}
+			matches[row.Series] = m
+		}
+
+		switch {
+		case m.reason != "":
+			report.Unmatched = append(report.Unmatched, Unmatched{Row: row, Reason: m.reason})
+			continue
+		case len(m.candidates) > 1:
+			report.Ambiguous = append(report.Ambiguous, Ambiguous{Row: row, Candidates: m.candidates})
+			continue
+		}
+
+		comic, ok := findIssue(m.comics, row.Issue)
+		if !ok {
+			report.Unmatched = append(report.Unmatched, Unmatched{Row: row, Reason: fmt.Sprintf("no issue %s in series %d", row.Issue, m.candidates[0].Id)})
+			continue
+		}
+
+		err := i.apply(ctx, row, comic)
+		if err != nil {
+			return Report{}, err
+		}
+
+		report.Matched = append(report.Matched, Match{Row: row, Series: comic.SeriesId, ComicId: comic.Id})
+	}
+
+	return report, nil
+}
+
+func (i *Importer) apply(ctx context.Context, row Row, comic comicshelf.Comic) error {
+	if i.dryRun {
+		return nil
+	}
+
+	err := i.users.Follow(ctx, i.userId, comicshelf.Follow{Kind: comicshelf.FollowSeries, Id: comic.SeriesId})
+	if err != nil {
+		return fmt.Errorf("could not follow series %d: %w", comic.SeriesId, err)
+	}
+
+	if !row.Read {
+		return nil
+	}
+
+	readAt := row.ReadAt
+	if readAt.IsZero() {
+		readAt = time.Now()
+	}
+
+	_, err = i.users.SetIssueState(ctx, i.userId, comic.Id, comicshelf.StateRead, readAt)
+	if err != nil {
+		return fmt.Errorf("could not mark comic %d as read: %w", comic.Id, err)
+	}
+
+	return nil
+}
+
+func (i *Importer) matchSeries(ctx context.Context, title string) (*seriesMatch, error) {
+	name, year := splitYear(title)
+	if name == "" {
+		return &seriesMatch{reason: "no series title"}, nil
+	}
+
+	found, err := i.finder.SearchSeries(ctx, name)
+	if err != nil {
+		return nil, fmt.Errorf("could not search for series %s: %w", title, err)
+	}
+
+	type scored struct {
+		series comicshelf.Series
+		score  float64
+	}
+
+	var candidates []scored
+	for _, s := range found {
+		candidateName, _ := splitYear(s.Title)
+		score := i.scorer.Similarity(name, candidateName)
+		if score < minSimilarity {
+			continue
+		}
+
+		if year != 0 && s.StartYear != year {
+			continue
+		}
+
+		candidates = append(candidates, scored{series: s, score: score})
+	}
+
+	if len(candidates) == 0 {
+		return &seriesMatch{reason: fmt.Sprintf("no series like %s", title)}, nil
+	}
+
+	sort.Slice(candidates, func(a, b int) bool {
+		return candidates[a].score > candidates[b].score
+	})
+
+	best := candidates[0]
+	tied := []comicshelf.Series{best.series}
+	for _, c := range candidates[1:] {
+		if best.score-c.score <= ambiguityMargin {
+			tied = append(tied, c.series)
+		}
+	}
+
+	if len(tied) > 1 {
+		return &seriesMatch{candidates: tied}, nil
+	}
+
+	comics, err := i.finder.GetComicsWithinSeries(ctx, best.series.Id)
+	if err != nil {
+		return nil, fmt.Errorf("could not get comics within series %d: %w", best.series.Id, err)
+	}
+
+	return &seriesMatch{candidates: tied, comics: comics}, nil
+}
+
+// splitYear separates a title such as "Captain Marvel (2019 - 2023)" into its name and start year.
+func splitYear(title string) (string, int) {
+	var year int
+	if m := yearPattern.FindStringSubmatch(title); m != nil {
+		year, _ = strconv.Atoi(m[1])
+	}
+
+	return strings.TrimSpace(yearPattern.ReplaceAllString(title, "")), year
+}
+
+func findIssue(comics []comicshelf.Comic, issue string) (comicshelf.Comic, bool) {
+	n, err := strconv.Atoi(issue)
+	if err != nil {
+		return comicshelf.Comic{}, false
+	}
+
+	for _, c := range comics {
+		if c.IssuerNumber == n {
+			return c, true
+		}
+	}
+
+	return comicshelf.Comic{}, false
+}
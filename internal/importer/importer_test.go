@@ -0,0 +1,103 @@
+package importer
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jakedegiovanni/comicshelf"
+	"github.com/jakedegiovanni/comicshelf/internal/search"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeFinder struct {
+	series []comicshelf.Series
+	comics map[int][]comicshelf.Comic
+}
+
+func (f fakeFinder) SearchSeries(ctx context.Context, prefix string) ([]comicshelf.Series, error) {
+	return f.series, nil
+}
+
+func (f fakeFinder) GetComicsWithinSeries(ctx context.Context, id int) ([]comicshelf.Comic, error) {
+	return f.comics[id], nil
+}
+
+type fakeUsers struct {
+	comicshelf.UserService
+	follows comicshelf.Set[comicshelf.Follow]
+	read    map[int]time.Time
+}
+
+func (f *fakeUsers) Follow(ctx context.Context, userId int, follow comicshelf.Follow) error {
+	f.follows.Put(follow)
+	return nil
+}
+
+func (f *fakeUsers) SetIssueState(ctx context.Context, userId, comicId int, state comicshelf.IssueState, at time.Time) (comicshelf.IssueStates, error) {
+	f.read[comicId] = at
+	return comicshelf.IssueStates{state: at}, nil
+}
+
+func TestParseCSV(t *testing.T) {
+	rows, err := Parse(FormatCSV, strings.NewReader("series,issue,read_date\nCaptain Marvel (2019),#3,2023-04-01\nThor,1,\n"))
+	require.Nil(t, err)
+
+	assert.Equal(t, []Row{
+		{Line: 2, Series: "Captain Marvel (2019)", Issue: "3", Read: true, ReadAt: time.Date(2023, 4, 1, 0, 0, 0, 0, time.UTC)},
+		{Line: 3, Series: "Thor", Issue: "1"},
+	}, rows)
+}
+
+func TestParseCLZ(t *testing.T) {
+	rows, err := Parse(FormatCLZ, strings.NewReader("\ufeffSeries,Issue Nr,Publisher,Read It\nThor,2,Marvel,Yes\nThor,3,Marvel,No\n"))
+	require.Nil(t, err)
+
+	require.Len(t, rows, 2)
+	assert.True(t, rows[0].Read)
+	assert.False(t, rows[1].Read)
+	assert.Equal(t, "2", rows[0].Issue)
+}
+
+func TestParseMissingColumn(t *testing.T) {
+	_, err := Parse(FormatCSV, strings.NewReader("title,issue\n"))
+	assert.ErrorIs(t, err, comicshelf.ErrInvalidInput)
+}
+
+func TestImport(t *testing.T) {
+	finder := fakeFinder{
+		series: []comicshelf.Series{
+			{Id: 1, Title: "Captain Marvel (2019 - 2023)", StartYear: 2019},
+			{Id: 2, Title: "Captain Marvel (2016)", StartYear: 2016},
+			{Id: 3, Title: "Captain America (2018 - 2021)", StartYear: 2018},
+		},
+		comics: map[int][]comicshelf.Comic{
+			1: {{Id: 10, SeriesId: 1, IssuerNumber: 3}},
+		},
+	}
+	users := &fakeUsers{follows: make(comicshelf.Set[comicshelf.Follow]), read: make(map[int]time.Time)}
+	readAt := time.Date(2023, 4, 1, 0, 0, 0, 0, time.UTC)
+
+	report, err := New(finder, ScorerFunc(search.Similarity), users, 0, false).Import(context.Background(), []Row{
+		{Line: 2, Series: "Captain Marvel (2019)", Issue: "3", Read: true, ReadAt: readAt},
+		{Line: 3, Series: "Captain Marvel (2019)", Issue: "99"},
+		{Line: 4, Series: "Captain Marvel", Issue: "1"},
+		{Line: 5, Series: "Daredevil", Issue: "1"},
+	})
+	require.Nil(t, err)
+
+	require.Len(t, report.Matched, 1)
+	assert.Equal(t, 10, report.Matched[0].ComicId)
+
+	require.Len(t, report.Ambiguous, 1)
+	assert.Equal(t, 4, report.Ambiguous[0].Row.Line)
+	assert.Len(t, report.Ambiguous[0].Candidates, 2)
+
+	require.Len(t, report.Unmatched, 2)
+	assert.Equal(t, []int{3, 5}, []int{report.Unmatched[0].Row.Line, report.Unmatched[1].Row.Line})
+
+	assert.True(t, users.follows.Has(comicshelf.Follow{Kind: comicshelf.FollowSeries, Id: 1}))
+	assert.Equal(t, readAt, users.read[10])
+}
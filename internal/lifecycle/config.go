@@ -0,0 +1,8 @@
+package lifecycle
+
+import "time"
+
+type Config struct {
+	// StopTimeout bounds how long each worker gets to stop once the main run has returned.
+	StopTimeout time.Duration `mapstructure:"stop_timeout"`
+}
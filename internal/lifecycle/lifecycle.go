@@ -0,0 +1,104 @@
+// Package lifecycle runs the application until it is asked to stop, then stops everything behind it in order.
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+// defaultStopTimeout applies when no stop timeout is configured.
+const defaultStopTimeout = 10 * time.Second
+
+type stopper struct {
+	name string
+	stop func(context.Context) error
+}
+
+// Manager stops workers in the reverse of the order they were registered,
+// so something registered first, such as the database, is stopped after everything that writes to it.
+type Manager struct {
+	cfg      *Config
+	stoppers []stopper
+	signals  []os.Signal
+}
+
+func New(cfg *Config) *Manager {
+	return &Manager{
+		cfg:     cfg,
+		signals: []os.Signal{os.Interrupt, syscall.SIGTERM},
+	}
+}
+
+// OnStop registers a worker to be stopped once the main run returns.
+func (m *Manager) OnStop(name string, stop func(context.Context) error) {
+	m.stoppers = append(m.stoppers, stopper{name: name, stop: stop})
+}
+
+// Run calls run with a context cancelled by SIGINT or SIGTERM, then once run returns stops every worker.
+// A worker failing or timing out does not stop the ones after it, all errors are returned together.
+// A second signal while stopping falls through to the default handling and kills the process.
+func (m *Manager) Run(ctx context.Context, run func(context.Context) error) error {
+	ctx, cancel := signal.NotifyContext(ctx, m.signals...)
+
+	err := run(ctx)
+	if ctx.Err() != nil {
+		slog.Info("shutdown requested")
+	}
+	cancel()
+
+	return m.Stop(err)
+}
+
+// Stop stops every registered worker and returns their errors joined with err.
+// It is for bailing out when startup fails part way, after some workers have already been registered.
+func (m *Manager) Stop(err error) error {
+	errs := []error{err}
+	for i := len(m.stoppers) - 1; i >= 0; i-- {
+		errs = append(errs, m.stop(m.stoppers[i]))
+	}
+
+	err = errors.Join(errs...)
+	if err != nil {
+		slog.Error("shutdown finished with errors", slog.String("err", err.Error()))
+		return err
+	}
+
+	slog.Info("shutdown complete")
+	return nil
+}
+
+func (m *Manager) stop(s stopper) error {
+	timeout := m.cfg.StopTimeout
+	if timeout <= 0 {
+		timeout = defaultStopTimeout
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	start := time.Now()
+	done := make(chan error, 1)
+	go func() {
+		done <- s.stop(ctx)
+	}()
+
+	var err error
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	if err != nil {
+		return fmt.Errorf("could not stop %s: %w", s.name, err)
+	}
+
+	slog.Debug("stopped", slog.String("worker", s.name), slog.Duration("took", time.Since(start)))
+	return nil
+}
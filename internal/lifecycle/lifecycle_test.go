@@ -0,0 +1,67 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunStopsInReverseOrder(t *testing.T) {
+	m := New(&Config{StopTimeout: time.Second})
+
+	var order []string
+	for _, name := range []string{"db", "catalogue", "tracing"} {
+		name := name
+		m.OnStop(name, func(ctx context.Context) error {
+			order = append(order, name)
+			return nil
+		})
+	}
+
+	err := m.Run(context.Background(), func(ctx context.Context) error { return nil })
+	require.NoError(t, err)
+	assert.Equal(t, []string{"tracing", "catalogue", "db"}, order)
+}
+
+func TestRunReachesEveryWorker(t *testing.T) {
+	m := New(&Config{StopTimeout: 10 * time.Millisecond})
+
+	flushed := false
+	m.OnStop("db", func(ctx context.Context) error {
+		flushed = true
+		return nil
+	})
+	m.OnStop("stuck", func(ctx context.Context) error {
+		time.Sleep(time.Second)
+		return nil
+	})
+	m.OnStop("broken", func(ctx context.Context) error {
+		return errors.New("boom")
+	})
+
+	runErr := errors.New("listen failed")
+	err := m.Run(context.Background(), func(ctx context.Context) error { return runErr })
+
+	assert.True(t, flushed)
+	assert.ErrorIs(t, err, runErr)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.ErrorContains(t, err, "could not stop broken: boom")
+}
+
+func TestRunCancelsOnSignal(t *testing.T) {
+	m := New(&Config{StopTimeout: time.Second})
+	m.signals = []os.Signal{syscall.SIGUSR1}
+
+	err := m.Run(context.Background(), func(ctx context.Context) error {
+		require.NoError(t, syscall.Kill(syscall.Getpid(), syscall.SIGUSR1))
+		<-ctx.Done()
+		return nil
+	})
+	require.NoError(t, err)
+}
@@ -0,0 +1,96 @@
+// Package metrics holds the prometheus collectors for the whole application and the handler exposing them.
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/jakedegiovanni/comicshelf/internal/comicclient"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "comicshelf"
+
+// Registry is separate from the prometheus default so only what is declared here is exposed.
+var Registry = prometheus.NewRegistry()
+
+var factory = promauto.With(Registry)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+}
+
+var (
+	HTTPRequests = factory.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "request_duration_seconds",
+		Help:      "Time taken to serve requests, by chi route pattern.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	UpstreamRequests = factory.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "upstream",
+		Name:      "request_duration_seconds",
+		Help:      "Time taken by requests to the comic provider, by response status.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"host", "status"})
+
+	CacheLookups = factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "cache",
+		Name:      "lookups_total",
+		Help:      "Upstream response cache lookups, by result: hit, miss or not_modified when a hit was confirmed by a 304.",
+	}, []string{"result"})
+
+	FlushDuration = factory.NewHistogram(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "filedb",
+		Name:      "flush_duration_seconds",
+		Help:      "Time taken to write the database to disk.",
+		Buckets:   prometheus.ExponentialBuckets(0.001, 4, 8),
+	})
+
+	FlushFailures = factory.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "filedb",
+		Name:      "flush_failures_total",
+		Help:      "Writes of the database to disk that failed.",
+	})
+)
+
+const (
+	CacheHit         = "hit"
+	CacheMiss        = "miss"
+	CacheNotModified = "not_modified"
+)
+
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{Registry: Registry})
+}
+
+// ClientMiddleware times every upstream request, a failed round trip is recorded with the status "error".
+func ClientMiddleware() comicclient.Middleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return comicclient.MiddlewareFn(func(req *http.Request) (*http.Response, error) {
+			start := time.Now()
+			resp, err := next.RoundTrip(req)
+
+			status := "error"
+			if err == nil {
+				status = strconv.Itoa(resp.StatusCode)
+			}
+			UpstreamRequests.WithLabelValues(req.URL.Host, status).Observe(time.Since(start).Seconds())
+
+			return resp, err
+		})
+	}
+}
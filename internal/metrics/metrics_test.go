@@ -0,0 +1,42 @@
+package metrics
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/jakedegiovanni/comicshelf/internal/comicclient"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClientMiddleware(t *testing.T) {
+	UpstreamRequests.Reset()
+
+	fail := false
+	transport := ClientMiddleware()(comicclient.MiddlewareFn(func(req *http.Request) (*http.Response, error) {
+		if fail {
+			return nil, errors.New("connection refused")
+		}
+		return &http.Response{StatusCode: http.StatusNotModified}, nil
+	}))
+
+	req, err := http.NewRequest(http.MethodGet, "https://example.com/comics", nil)
+	require.NoError(t, err)
+
+	_, err = transport.RoundTrip(req)
+	require.NoError(t, err)
+
+	fail = true
+	_, err = transport.RoundTrip(req)
+	require.Error(t, err)
+
+	assert.Equal(t, 2, testutil.CollectAndCount(UpstreamRequests))
+	for _, status := range []string{"304", "error"} {
+		h, ok := UpstreamRequests.WithLabelValues("example.com", status).(prometheus.Histogram)
+		require.True(t, ok)
+		assert.Equal(t, 1, testutil.CollectAndCount(h), status)
+	}
+}
@@ -0,0 +1,47 @@
+// Package release works out which week of releases a provider is showing at a given time.
+package release
+
+import (
+	"time"
+	_ "time/tzdata" // providers are configured with named zones, don't rely on the host having them
+)
+
+// Rules describe how a provider groups its releases into weeks.
+type Rules struct {
+	// WeekStart is the first day of a week in the provider's date ranges.
+	WeekStart time.Weekday `mapstructure:"week_start"`
+	// CutOff is the day a new week of releases shows up, until then the previous week is still shown.
+	CutOff time.Weekday `mapstructure:"cut_off"`
+	// Location is the timezone the provider's schedule runs in, UTC when unset.
+	Location *time.Location `mapstructure:"timezone"`
+}
+
+// Offset shifts a week onto the one whose releases are shown in its place,
+// such as a digital service lagging the print releases.
+type Offset struct {
+	Months int `mapstructure:"months"`
+	Weeks  int `mapstructure:"weeks"`
+}
+
+// Week returns the first and last day of the releases showing at t, after applying the offset.
+// Both are midnight in the rules' location.
+func (r Rules) Week(t time.Time, offset Offset) (time.Time, time.Time) {
+	loc := r.Location
+	if loc == nil {
+		loc = time.UTC
+	}
+
+	t = t.In(loc)
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+
+	// every day of a shown week maps onto its cut off so the offset lands them all in the same week
+	day = day.AddDate(0, 0, -daysSince(day.Weekday(), r.CutOff))
+	day = day.AddDate(0, offset.Months, 7*offset.Weeks)
+	day = day.AddDate(0, 0, -daysSince(day.Weekday(), r.WeekStart))
+
+	return day, day.AddDate(0, 0, 6)
+}
+
+func daysSince(day, since time.Weekday) int {
+	return (int(day) - int(since) + 7) % 7
+}
@@ -0,0 +1,139 @@
+package release
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWeek(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	require.NoError(t, err)
+	london, err := time.LoadLocation("Europe/London")
+	require.NoError(t, err)
+
+	marvel := Rules{WeekStart: time.Sunday, CutOff: time.Monday, Location: newYork}
+	wednesdays := Rules{WeekStart: time.Wednesday, CutOff: time.Wednesday, Location: london}
+	unlimited := Offset{Months: -3, Weeks: -1}
+
+	tests := []struct {
+		name   string
+		rules  Rules
+		offset Offset
+		t      time.Time
+		first  string
+		last   string
+	}{
+		{
+			name:  "cut off day",
+			rules: marvel,
+			t:     time.Date(2026, time.October, 19, 12, 0, 0, 0, newYork),
+			first: "2026-10-18 00:00 EDT",
+			last:  "2026-10-24 00:00 EDT",
+		},
+		{
+			name:  "before cut off shows previous week",
+			rules: marvel,
+			t:     time.Date(2026, time.October, 25, 12, 0, 0, 0, newYork),
+			first: "2026-10-18 00:00 EDT",
+			last:  "2026-10-24 00:00 EDT",
+		},
+		{
+			name:  "instant is read in the provider's timezone",
+			rules: marvel,
+			t:     time.Date(2026, time.October, 26, 2, 0, 0, 0, time.UTC),
+			first: "2026-10-18 00:00 EDT",
+			last:  "2026-10-24 00:00 EDT",
+		},
+		{
+			name:  "week spans new year",
+			rules: marvel,
+			t:     time.Date(2026, time.January, 1, 9, 0, 0, 0, newYork),
+			first: "2025-12-28 00:00 EST",
+			last:  "2026-01-03 00:00 EST",
+		},
+		{
+			name:  "sunday after new year is still the old week",
+			rules: marvel,
+			t:     time.Date(2026, time.January, 4, 9, 0, 0, 0, newYork),
+			first: "2025-12-28 00:00 EST",
+			last:  "2026-01-03 00:00 EST",
+		},
+		{
+			name:  "week starting on dst change",
+			rules: marvel,
+			t:     time.Date(2026, time.March, 9, 0, 30, 0, 0, newYork),
+			first: "2026-03-08 00:00 EST",
+			last:  "2026-03-14 00:00 EDT",
+		},
+		{
+			name:  "dst change before cut off",
+			rules: marvel,
+			t:     time.Date(2026, time.March, 8, 3, 30, 0, 0, newYork),
+			first: "2026-03-01 00:00 EST",
+			last:  "2026-03-07 00:00 EST",
+		},
+		{
+			name:  "week starting on dst end",
+			rules: marvel,
+			t:     time.Date(2026, time.November, 2, 23, 59, 0, 0, newYork),
+			first: "2026-11-01 00:00 EDT",
+			last:  "2026-11-07 00:00 EST",
+		},
+		{
+			name:   "offset",
+			rules:  marvel,
+			offset: unlimited,
+			t:      time.Date(2026, time.October, 19, 12, 0, 0, 0, newYork),
+			first:  "2026-07-12 00:00 EDT",
+			last:   "2026-07-18 00:00 EDT",
+		},
+		{
+			name:   "offset crosses year",
+			rules:  marvel,
+			offset: unlimited,
+			t:      time.Date(2026, time.January, 7, 12, 0, 0, 0, newYork),
+			first:  "2025-09-28 00:00 EDT",
+			last:   "2025-10-04 00:00 EDT",
+		},
+		{
+			name:   "offset is the same for every day of the week",
+			rules:  marvel,
+			offset: unlimited,
+			t:      time.Date(2026, time.January, 11, 12, 0, 0, 0, newYork),
+			first:  "2025-09-28 00:00 EDT",
+			last:   "2025-10-04 00:00 EDT",
+		},
+		{
+			name:  "mid week cut off",
+			rules: wednesdays,
+			t:     time.Date(2026, time.December, 29, 12, 0, 0, 0, london),
+			first: "2026-12-23 00:00 GMT",
+			last:  "2026-12-29 00:00 GMT",
+		},
+		{
+			name:  "mid week cut off crosses year",
+			rules: wednesdays,
+			t:     time.Date(2026, time.December, 30, 0, 0, 0, 0, london),
+			first: "2026-12-30 00:00 GMT",
+			last:  "2027-01-05 00:00 GMT",
+		},
+		{
+			name:  "unset location is utc",
+			rules: Rules{WeekStart: time.Sunday, CutOff: time.Sunday},
+			t:     time.Date(2026, time.October, 18, 1, 0, 0, 0, newYork),
+			first: "2026-10-18 00:00 UTC",
+			last:  "2026-10-24 00:00 UTC",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			first, last := test.rules.Week(test.t, test.offset)
+			assert.Equal(t, test.first, first.Format("2006-01-02 15:04 MST"))
+			assert.Equal(t, test.last, last.Format("2006-01-02 15:04 MST"))
+		})
+	}
+}
@@ -0,0 +1,275 @@
+package search
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/jakedegiovanni/comicshelf"
+)
+
+const (
+	exactWeight  = 1.0
+	prefixWeight = 0.8
+	typoWeight   = 0.5
+	// titlePrefixBonus rewards documents whose title starts with the whole query, mirroring marvel's titleStartsWith.
+	titlePrefixBonus = 1.0
+)
+
+type docKey struct {
+	kind comicshelf.SearchKind
+	id   int
+}
+
+type document struct {
+	key       docKey
+	title     string
+	thumbnail string
+	length    int
+}
+
+// Index is an in-memory inverted index over comic and series titles.
+type Index struct {
+	mu       *sync.RWMutex
+	docs     map[docKey]document
+	postings map[string]map[docKey]int
+	// initials groups the terms by their first letter, a query token is only compared against terms sharing it
+	// so a search does not run an edit distance over the whole vocabulary
+	initials map[rune]comicshelf.Set[string]
+}
+
+func NewIndex() *Index {
+	return &Index{
+		mu:       new(sync.RWMutex),
+		docs:     make(map[docKey]document),
+		postings: make(map[string]map[docKey]int),
+		initials: make(map[rune]comicshelf.Set[string]),
+	}
+}
+
+func (i *Index) AddComics(comics ...comicshelf.Comic) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	for _, c := range comics {
+		i.add(docKey{kind: comicshelf.SearchComic, id: c.Id}, c.Title, c.Thumbnail)
+	}
+}
+
+func (i *Index) AddSeries(series ...comicshelf.Series) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	for _, s := range series {
+		i.add(docKey{kind: comicshelf.SearchSeries, id: s.Id}, s.Title, s.Thumbnail)
+	}
+}
+
+func (i *Index) Len() int {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
+	return len(i.docs)
+}
+
+func (i *Index) add(key docKey, title, thumbnail string) {
+	if old, ok := i.docs[key]; ok {
+		if old.title == title {
+			return
+		}
+		i.remove(old)
+	}
+
+	tokens := tokenize(title)
+	i.docs[key] = document{key: key, title: title, thumbnail: thumbnail, length: len(tokens)}
+
+	for _, token := range tokens {
+		posting, ok := i.postings[token]
+		if !ok {
+			posting = make(map[docKey]int)
+			i.postings[token] = posting
+
+			initial := firstRune(token)
+			if i.initials[initial] == nil {
+				i.initials[initial] = make(comicshelf.Set[string])
+			}
+			i.initials[initial].Put(token)
+		}
+		posting[key]++
+	}
+}
+
+func (i *Index) remove(doc document) {
+	for _, token := range tokenize(doc.title) {
+		delete(i.postings[token], doc.key)
+		if len(i.postings[token]) == 0 {
+			delete(i.postings, token)
+
+			initial := firstRune(token)
+			i.initials[initial].Delete(token)
+			if len(i.initials[initial]) == 0 {
+				delete(i.initials, initial)
+			}
+		}
+	}
+	delete(i.docs, doc.key)
+}
+
+// Search ranks indexed documents against the query. Each query token matches index terms exactly, as a prefix, or
+// within a small edit distance, weighted by how rare the term is. Only terms starting with the same letter as the
+// token are candidates, like most search engines a typo in the first letter is not forgiven.
+func (i *Index) Search(query string, limit int) []comicshelf.SearchResult {
+	tokens := tokenize(query)
+	if len(tokens) == 0 {
+		return nil
+	}
+
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
+	scores := make(map[docKey]float64)
+	for _, token := range tokens {
+		best := make(map[docKey]float64)
+		for term := range i.initials[firstRune(token)] {
+			posting := i.postings[term]
+			weight := matchWeight(token, term)
+			if weight == 0 {
+				continue
+			}
+
+			idf := math.Log(1 + float64(len(i.docs))/float64(len(posting)))
+			for key, tf := range posting {
+				score := weight * idf * float64(tf)
+				if score > best[key] {
+					best[key] = score
+				}
+			}
+		}
+
+		for key, score := range best {
+			scores[key] += score
+		}
+	}
+
+	normalised := strings.Join(tokens, " ")
+	results := make([]comicshelf.SearchResult, 0, len(scores))
+	for key, score := range scores {
+		doc := i.docs[key]
+		score = score / math.Sqrt(float64(doc.length))
+		if strings.HasPrefix(strings.Join(tokenize(doc.title), " "), normalised) {
+			score += titlePrefixBonus
+		}
+
+		results = append(results, comicshelf.SearchResult{
+			Kind:      key.kind,
+			Id:        key.id,
+			Title:     doc.title,
+			Thumbnail: doc.thumbnail,
+			Score:     score,
+		})
+	}
+
+	sort.Slice(results, func(a, b int) bool {
+		if results[a].Score != results[b].Score {
+			return results[a].Score > results[b].Score
+		}
+		return results[a].Title < results[b].Title
+	})
+
+	if limit > 0 && len(results) > limit {
+		results = results[:limit]
+	}
+
+	return results
+}
+
+func matchWeight(token, term string) float64 {
+	switch {
+	case token == term:
+		return exactWeight
+	case strings.HasPrefix(term, token):
+		return prefixWeight
+	}
+
+	maxEdits := allowedEdits(token)
+	if maxEdits == 0 {
+		return 0
+	}
+
+	if abs(len(token)-len(term)) > maxEdits {
+		return 0
+	}
+
+	if levenshtein(token, term) <= maxEdits {
+		return typoWeight
+	}
+
+	return 0
+}
+
+// allowedEdits keeps short tokens strict, otherwise every two letter word would match everything.
+func allowedEdits(token string) int {
+	switch n := len([]rune(token)); {
+	case n >= 8:
+		return 2
+	case n >= 4:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// Similarity scores how alike two titles are from 0 to 1, ignoring case and punctuation.
+func Similarity(a, b string) float64 {
+	a, b = strings.Join(tokenize(a), " "), strings.Join(tokenize(b), " ")
+	longest := max(len([]rune(a)), len([]rune(b)))
+	if longest == 0 {
+		return 1
+	}
+
+	return 1 - float64(levenshtein(a, b))/float64(longest)
+}
+
+func tokenize(s string) []string {
+	return strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+func firstRune(s string) rune {
+	r, _ := utf8.DecodeRuneInString(s)
+	return r
+}
+
+func levenshtein(a, b string) int {
+	ar, br := []rune(a), []rune(b)
+	prev := make([]int, len(br)+1)
+	curr := make([]int, len(br)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ar); i++ {
+		curr[0] = i
+		for j := 1; j <= len(br); j++ {
+			cost := 1
+			if ar[i-1] == br[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+
+	return prev[len(br)]
+}
+
+func abs(i int) int {
+	if i < 0 {
+		return -i
+	}
+	return i
+}
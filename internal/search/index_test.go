@@ -0,0 +1,80 @@
+package search
+
+import (
+	"testing"
+
+	"github.com/jakedegiovanni/comicshelf"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testIndex() *Index {
+	idx := NewIndex()
+	idx.AddSeries(
+		comicshelf.Series{Id: 1, Title: "The Amazing Spider-Man (2022 - Present)"},
+		comicshelf.Series{Id: 2, Title: "Spider-Gwen (2015 - 2016)"},
+		comicshelf.Series{Id: 3, Title: "Fantastic Four (2022 - Present)"},
+	)
+	idx.AddComics(
+		comicshelf.Comic{Id: 10, Title: "The Amazing Spider-Man (2022) #1"},
+		comicshelf.Comic{Id: 11, Title: "Fantastic Four (2022) #5"},
+	)
+	return idx
+}
+
+func ids(results []comicshelf.SearchResult) []int {
+	out := make([]int, 0, len(results))
+	for _, r := range results {
+		out = append(out, r.Id)
+	}
+	return out
+}
+
+func TestIndexSearch(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		want  []int
+	}{
+		{name: "exact", query: "fantastic four", want: []int{3, 11}},
+		{name: "prefix", query: "spid", want: []int{2, 1, 10}},
+		{name: "typo", query: "fantastik", want: []int{3, 11}},
+		{name: "typo in first letter", query: "gantastic", want: []int{}},
+		{name: "no match", query: "batman", want: []int{}},
+		{name: "empty", query: "  ", want: []int{}},
+	}
+
+	idx := testIndex()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, ids(idx.Search(tt.query, 0)))
+		})
+	}
+}
+
+func TestIndexReplacesRetitledDocuments(t *testing.T) {
+	idx := testIndex()
+	idx.AddSeries(comicshelf.Series{Id: 3, Title: "Fantastic Five"})
+
+	results := idx.Search("five", 0)
+	require.Len(t, results, 1)
+	assert.Equal(t, 3, results[0].Id)
+	assert.Equal(t, 5, idx.Len())
+
+	// terms no title uses any more are dropped from the candidates too
+	idx.AddSeries(comicshelf.Series{Id: 2, Title: "Silk"})
+	assert.NotContains(t, idx.initials, 'g')
+	assert.Empty(t, idx.Search("gwen", 0))
+}
+
+func TestLevenshtein(t *testing.T) {
+	assert.Equal(t, 0, levenshtein("spider", "spider"))
+	assert.Equal(t, 1, levenshtein("spider", "spyder"))
+	assert.Equal(t, 3, levenshtein("", "abc"))
+}
+
+func TestSimilarity(t *testing.T) {
+	assert.Equal(t, 1.0, Similarity("Spider-Man", "spider man"))
+	assert.Equal(t, 0.0, Similarity("abc", "xyz"))
+	assert.InDelta(t, 0.9, Similarity("spider-men", "spider-man"), 0.001)
+}
@@ -0,0 +1,69 @@
+package search
+
+import (
+	"context"
+	"log/slog"
+	"unicode/utf8"
+
+	"github.com/jakedegiovanni/comicshelf"
+	"golang.org/x/sync/errgroup"
+)
+
+var _ comicshelf.SearchService = (*Service)(nil)
+
+const (
+	// minUpstreamQuery avoids asking the upstream for every keystroke of a live search.
+	minUpstreamQuery = 3
+	resultLimit      = 30
+)
+
+// Upstream is a provider able to look up comics and series by the start of their title.
+type Upstream interface {
+	SearchComics(ctx context.Context, prefix string) ([]comicshelf.Comic, error)
+	SearchSeries(ctx context.Context, prefix string) ([]comicshelf.Series, error)
+}
+
+type Service struct {
+	upstream Upstream
+	index    *Index
+}
+
+func New(upstream Upstream, index *Index) *Service {
+	return &Service{
+		upstream: upstream,
+		index:    index,
+	}
+}
+
+// Search adds upstream title matches to the index and then ranks everything the index holds. Upstream failures only
+// degrade the results, the local index is still searched.
+func (s *Service) Search(ctx context.Context, query string) ([]comicshelf.SearchResult, error) {
+	if utf8.RuneCountInString(query) >= minUpstreamQuery {
+		g, ctx := errgroup.WithContext(ctx)
+		g.Go(func() error {
+			comics, err := s.upstream.SearchComics(ctx, query)
+			if err != nil {
+				return err
+			}
+
+			s.index.AddComics(comics...)
+			return nil
+		})
+		g.Go(func() error {
+			series, err := s.upstream.SearchSeries(ctx, query)
+			if err != nil {
+				return err
+			}
+
+			s.index.AddSeries(series...)
+			return nil
+		})
+
+		err := g.Wait()
+		if err != nil {
+			slog.Warn("upstream search failed, using local index only", slog.String("query", query), slog.String("err", err.Error()))
+		}
+	}
+
+	return s.index.Search(query, resultLimit), nil
+}
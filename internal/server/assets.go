@@ -0,0 +1,283 @@
+package server
+
+//go:generate go run gen_vendor.go
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"mime"
+	"net/http"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/andybalholm/brotli"
+)
+
+const staticPrefix = "/static/"
+
+// vendored are the third party files gen_vendor.go downloads.
+// Pages still render without them, only the htmx driven parts stop working, so a missing one is warned about rather than fatal.
+var vendored = []string{"vendor/htmx.min.js"}
+
+// cssURL matches the url() references in stylesheets so they can be pointed at hashed names too.
+var cssURL = regexp.MustCompile(`url\(\s*['"]?([^'")]+?)['"]?\s*\)`)
+
+// fontTypes fills in the font types mime does not know about on every platform.
+var fontTypes = map[string]string{
+	".ttf":   "font/ttf",
+	".woff":  "font/woff",
+	".woff2": "font/woff2",
+}
+
+// precompressors are tried in order of preference when a browser accepts more than one.
+var precompressors = []struct {
+	encoding string
+	writer   func(io.Writer) io.WriteCloser
+}{
+	{encoding: "br", writer: func(w io.Writer) io.WriteCloser {
+		return brotli.NewWriterLevel(w, brotli.BestCompression)
+	}},
+	{encoding: "gzip", writer: func(w io.Writer) io.WriteCloser {
+		gz, _ := gzip.NewWriterLevel(w, gzip.BestCompression)
+		return gz
+	}},
+}
+
+type encoded struct {
+	content []byte
+	etag    string
+}
+
+type asset struct {
+	contentType string
+	// encodings holds the file as is under "" and any compressed copies that came out smaller
+	encodings map[string]encoded
+	// immutable is set on the hashed name, the original name is still served but has to be revalidated
+	immutable bool
+}
+
+// assets holds the static files in memory under names carrying a hash of their content,
+// so browsers can cache them forever and still pick up a change as soon as a new build links to it.
+// The embedded files are fixed for a build, so compressing and hashing them once at startup
+// gives the same result as a build step without generated files to keep in sync.
+type assets struct {
+	fsys fs.FS
+	// precompress is left off in dev mode where files are reloaded on every edit
+	precompress bool
+
+	mu     sync.RWMutex
+	files  map[string]*asset
+	hashed map[string]string
+}
+
+func newAssets(fsys fs.FS, precompress bool) (*assets, error) {
+	a := &assets{fsys: fsys, precompress: precompress}
+	err := a.load()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, name := range vendored {
+		if !a.has(name) {
+			slog.Warn("vendored static file is missing, run go generate ./internal/server", slog.String("file", name))
+		}
+	}
+
+	return a, nil
+}
+
+// load reads every static file and swaps them all in at once, so a page never links to a mix of old and new names.
+func (a *assets) load() error {
+	var names []string
+	err := fs.WalkDir(a.fsys, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+
+		names = append(names, name)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("could not list static files: %w", err)
+	}
+
+	// stylesheets go last so everything they reference already has its hashed name
+	sort.SliceStable(names, func(i, j int) bool {
+		return path.Ext(names[i]) != ".css" && path.Ext(names[j]) == ".css"
+	})
+
+	next := &assets{
+		precompress: a.precompress,
+		files:       make(map[string]*asset, 2*len(names)),
+		hashed:      make(map[string]string, len(names)),
+	}
+
+	for _, name := range names {
+		content, err := fs.ReadFile(a.fsys, name)
+		if err != nil {
+			return fmt.Errorf("could not read static file %s: %w", name, err)
+		}
+
+		if path.Ext(name) == ".css" {
+			content, err = next.rewriteCSS(name, content)
+			if err != nil {
+				return err
+			}
+		}
+
+		next.add(name, content)
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.files, a.hashed = next.files, next.hashed
+	return nil
+}
+
+func (a *assets) add(name string, content []byte) {
+	ext := path.Ext(name)
+	contentType := mime.TypeByExtension(ext)
+	if contentType == "" {
+		contentType = fontTypes[ext]
+	}
+
+	digest := sha256.Sum256(content)
+	sum := hex.EncodeToString(digest[:])
+	hashed := strings.TrimSuffix(name, ext) + "." + sum[:8] + ext
+
+	// a strong etag has to differ between encodings of the same file
+	encodings := map[string]encoded{"": {content: content, etag: `"` + sum + `"`}}
+	if a.precompress && compressible(contentType) {
+		for _, c := range precompressors {
+			var buf bytes.Buffer
+			w := c.writer(&buf)
+			_, _ = w.Write(content)
+			_ = w.Close()
+
+			if buf.Len() < len(content) {
+				encodings[c.encoding] = encoded{content: buf.Bytes(), etag: `"` + sum + "-" + c.encoding + `"`}
+			}
+		}
+	}
+
+	a.hashed[name] = hashed
+	a.files[name] = &asset{contentType: contentType, encodings: encodings}
+	a.files[hashed] = &asset{contentType: contentType, encodings: encodings, immutable: true}
+}
+
+// compressible is whether a type is worth compressing, images and woff fonts already are.
+func compressible(contentType string) bool {
+	return strings.HasPrefix(contentType, "text/") ||
+		strings.Contains(contentType, "javascript") ||
+		strings.Contains(contentType, "json") ||
+		strings.Contains(contentType, "svg") ||
+		contentType == "font/ttf"
+}
+
+func (a *assets) rewriteCSS(name string, content []byte) ([]byte, error) {
+	var err error
+	content = cssURL.ReplaceAllFunc(content, func(match []byte) []byte {
+		ref := string(cssURL.FindSubmatch(match)[1])
+		if strings.HasPrefix(ref, "data:") || strings.Contains(ref, "//") || strings.HasPrefix(ref, "/") {
+			return match
+		}
+
+		target := path.Join(path.Dir(name), ref)
+		hashed, ok := a.hashed[target]
+		if !ok {
+			err = fmt.Errorf("%s references unknown static file %s", name, ref)
+			return match
+		}
+
+		return []byte(`url("` + staticPrefix + hashed + `")`)
+	})
+
+	return content, err
+}
+
+func (a *assets) has(name string) bool {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	_, ok := a.hashed[name]
+	return ok
+}
+
+// url is the path a page should link to for the named static file.
+func (a *assets) url(name string) (string, error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	hashed, ok := a.hashed[name]
+	if !ok {
+		return "", fmt.Errorf("unknown static file %s", name)
+	}
+
+	return staticPrefix + hashed, nil
+}
+
+func (a *assets) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	name := strings.TrimPrefix(r.URL.Path, staticPrefix)
+	a.mu.RLock()
+	f, ok := a.files[name]
+	a.mu.RUnlock()
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	h := w.Header()
+	if f.immutable {
+		// the original names are left to the cache policy for their path
+		h.Set("Cache-Control", "public, max-age=31536000, immutable")
+	}
+
+	if f.contentType != "" {
+		h.Set("Content-Type", f.contentType)
+	}
+
+	file := f.encodings[""]
+	if len(f.encodings) > 1 {
+		h.Add("Vary", "Accept-Encoding")
+
+		accepted := acceptedEncodings(r.Header.Get("Accept-Encoding"))
+		for _, c := range precompressors {
+			if e, ok := f.encodings[c.encoding]; ok && accepted[c.encoding] {
+				h.Set("Content-Encoding", c.encoding)
+				file = e
+				break
+			}
+		}
+	}
+
+	h.Set("ETag", file.etag)
+	http.ServeContent(w, r, name, time.Time{}, bytes.NewReader(file.content))
+}
+
+// acceptedEncodings reads an Accept-Encoding header, leaving out anything refused with q=0.
+func acceptedEncodings(header string) map[string]bool {
+	accepted := make(map[string]bool)
+	for _, part := range strings.Split(header, ",") {
+		encoding, params, _ := strings.Cut(part, ";")
+		if q, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if v, err := strconv.ParseFloat(q, 64); err == nil && v == 0 {
+				continue
+			}
+		}
+
+		accepted[strings.ToLower(strings.TrimSpace(encoding))] = true
+	}
+
+	return accepted
+}
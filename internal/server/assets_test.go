@@ -0,0 +1,114 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestAssets(t *testing.T) *assets {
+	t.Helper()
+
+	a, err := newAssets(fstest.MapFS{
+		"app.js":   {Data: []byte(strings.Repeat("console.log('comicshelf');\n", 100))},
+		"logo.png": {Data: []byte("\x89PNG not really")},
+		"app.css":  {Data: []byte(`body { background: url("logo.png"); }`)},
+	}, true)
+	require.NoError(t, err)
+	return a
+}
+
+func serveAsset(a *assets, name string, header http.Header) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, staticPrefix+name, nil)
+	for k, v := range header {
+		req.Header[k] = v
+	}
+
+	rec := httptest.NewRecorder()
+	a.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestAssetsEncodingNegotiation(t *testing.T) {
+	a := newTestAssets(t)
+
+	tests := []struct {
+		name           string
+		acceptEncoding string
+		want           string
+	}{
+		{name: "nothing accepted", acceptEncoding: "", want: ""},
+		{name: "gzip only", acceptEncoding: "gzip", want: "gzip"},
+		{name: "br preferred over gzip", acceptEncoding: "gzip, deflate, br", want: "br"},
+		{name: "br preferred whatever its q", acceptEncoding: "gzip;q=1.0, br;q=0.5", want: "br"},
+		{name: "br refused with q=0", acceptEncoding: "br;q=0, gzip", want: "gzip"},
+		{name: "everything refused", acceptEncoding: "br;q=0, gzip;q=0.0", want: ""},
+		{name: "unknown encoding", acceptEncoding: "zstd", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := serveAsset(a, "app.js", http.Header{"Accept-Encoding": {tt.acceptEncoding}})
+			require.Equal(t, http.StatusOK, rec.Code)
+			assert.Equal(t, tt.want, rec.Header().Get("Content-Encoding"))
+			assert.Equal(t, "Accept-Encoding", rec.Header().Get("Vary"))
+		})
+	}
+}
+
+func TestAssetsIncompressibleServedAsIs(t *testing.T) {
+	a := newTestAssets(t)
+
+	rec := serveAsset(a, "logo.png", http.Header{"Accept-Encoding": {"br, gzip"}})
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Empty(t, rec.Header().Get("Content-Encoding"))
+	assert.Empty(t, rec.Header().Get("Vary"))
+}
+
+func TestAssetsETags(t *testing.T) {
+	a := newTestAssets(t)
+
+	etags := make(map[string]bool)
+	for _, encoding := range []string{"", "gzip", "br"} {
+		etag := serveAsset(a, "app.js", http.Header{"Accept-Encoding": {encoding}}).Header().Get("ETag")
+		require.NotEmpty(t, etag)
+		assert.False(t, strings.HasPrefix(etag, "W/"), "etag is strong")
+		etags[etag] = true
+
+		rec := serveAsset(a, "app.js", http.Header{"Accept-Encoding": {encoding}, "If-None-Match": {etag}})
+		assert.Equal(t, http.StatusNotModified, rec.Code, encoding)
+		assert.Empty(t, rec.Body.Bytes())
+	}
+	assert.Len(t, etags, 3, "every encoding has its own etag")
+
+	// an etag from another encoding is a different representation
+	rec := serveAsset(a, "app.js", http.Header{"Accept-Encoding": {"br"}, "If-None-Match": {serveAsset(a, "app.js", nil).Header().Get("ETag")}})
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
+
+func TestAssetsHashedNames(t *testing.T) {
+	a := newTestAssets(t)
+
+	url, err := a.url("app.js")
+	require.NoError(t, err)
+	assert.Regexp(t, `^/static/app\.[0-9a-f]{8}\.js$`, url)
+
+	rec := serveAsset(a, strings.TrimPrefix(url, staticPrefix), nil)
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Header().Get("Cache-Control"), "immutable")
+
+	rec = serveAsset(a, "app.js", nil)
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Empty(t, rec.Header().Get("Cache-Control"), "the original name is left to the path's policy")
+
+	logo, err := a.url("logo.png")
+	require.NoError(t, err)
+	css, err := a.url("app.css")
+	require.NoError(t, err)
+	assert.Contains(t, serveAsset(a, strings.TrimPrefix(css, staticPrefix), nil).Body.String(), logo)
+}
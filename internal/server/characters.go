@@ -0,0 +1,23 @@
+package server
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/jakedegiovanni/comicshelf"
+)
+
+func (s *Server) registerCharacterRoutes(router chi.Router) {
+	router.Get("/{characterId}", s.handleCharacter)
+}
+
+func (s *Server) handleCharacter(w http.ResponseWriter, r *http.Request) {
+	renderReleases(s, w, r, releasesPage[comicshelf.Character]{
+		kind:   "character",
+		param:  "characterId",
+		tmpl:   s.characterTmpl,
+		get:    s.characters.GetCharacter,
+		comics: s.characters.GetComicsWithCharacter,
+		title:  func(c comicshelf.Character) string { return c.Name },
+	})
+}
@@ -1,40 +1,487 @@
 package server
 
 import (
+	"context"
+	"fmt"
 	"log/slog"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/jakedegiovanni/comicshelf"
+	"golang.org/x/sync/errgroup"
 )
 
+// releaseWindowMonths is how far either side of today creator and character pages look for issues.
+const releaseWindowMonths = 3
+
+type comicView struct {
+	Comic    comicshelf.Comic
+	Previous *comicshelf.Comic
+	Next     *comicshelf.Comic
+	Lists    []comicshelf.ReadingList
+}
+
+type releasesView[T any] struct {
+	Item     T
+	Released []comicshelf.Comic
+	Upcoming []comicshelf.Comic
+}
+
+// maxRangeWeeks bounds how many weeks a single range request fans out to.
+const maxRangeWeeks = 12
+
+const (
+	releaseUnlimited = "unlimited"
+	releasePrint     = "print"
+)
+
+type weekView struct {
+	Date   string
+	Comics []comicshelf.Comic
+}
+
+type weeklyView struct {
+	Release  string
+	Toggle   string
+	Previous string
+	Next     string
+	From     string
+	To       string
+	Weeks    []weekView
+	Filter   comicshelf.ComicFilter
+
+	// queries behind the week toggle, relative to the current page so they work for both the weekly comics and the pull list
+	ThisWeek          string
+	NextWeek          string
+	UnlimitedThisWeek string
+}
+
+// weekQuery and rangeQuery carry the filter along so moving between weeks keeps it.
+func weekQuery(release string, t time.Time, filter comicshelf.ComicFilter) string {
+	query := filterQuery(filter)
+	query.Set("release", release)
+	query.Set("date", t.Format(justTheDateFormat))
+	return "?" + query.Encode()
+}
+
+func rangeQuery(release string, from, to time.Time, filter comicshelf.ComicFilter) string {
+	query := filterQuery(filter)
+	query.Set("release", release)
+	query.Set("from", from.Format(justTheDateFormat))
+	query.Set("to", to.Format(justTheDateFormat))
+	return "?" + query.Encode()
+}
+
+func filterQuery(filter comicshelf.ComicFilter) url.Values {
+	query := url.Values{}
+	if filter.Creator != "" {
+		query.Set("creator", filter.Creator)
+	}
+	if filter.Character != "" {
+		query.Set("character", filter.Character)
+	}
+	if filter.MinPages > 0 {
+		query.Set("min_pages", strconv.Itoa(filter.MinPages))
+	}
+	if filter.MaxPrice > 0 {
+		query.Set("max_price", strconv.FormatFloat(filter.MaxPrice, 'f', -1, 64))
+	}
+
+	return query
+}
+
+func parseFilter(query url.Values) (comicshelf.ComicFilter, error) {
+	filter := comicshelf.ComicFilter{
+		Creator:   strings.TrimSpace(query.Get("creator")),
+		Character: strings.TrimSpace(query.Get("character")),
+	}
+
+	if v := query.Get("min_pages"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			return comicshelf.ComicFilter{}, fmt.Errorf("min_pages query is not a page count: %w", comicshelf.ErrInvalidInput)
+		}
+		filter.MinPages = n
+	}
+
+	if v := query.Get("max_price"); v != "" {
+		n, err := strconv.ParseFloat(v, 64)
+		if err != nil || n < 0 {
+			return comicshelf.ComicFilter{}, fmt.Errorf("max_price query is not a price: %w", comicshelf.ErrInvalidInput)
+		}
+		filter.MaxPrice = n
+	}
+
+	return filter, nil
+}
+
 func (s *Server) registerComicRoutes(router chi.Router) {
-	router.Use(queryDate())
-	router.Get("/", s.handleWeeklyComics)
+	router.With(queryDate()).Get("/", s.handleWeeklyComics)
+	router.With(queryDate()).Get("/pull", s.handlePullList)
+	router.Get("/{comicId}", s.handleComic)
 }
 
 func (s *Server) handleWeeklyComics(w http.ResponseWriter, r *http.Request) {
-	t, err := time.Parse(justTheDateFormat, r.URL.Query().Get("date"))
+	s.renderWeekly(w, r, "Weekly Comics", nil)
+}
+
+func (s *Server) handlePullList(w http.ResponseWriter, r *http.Request) {
+	s.renderWeekly(w, r, "Pull List", func(v viewer, comic comicshelf.Comic) bool {
+		return comicshelf.Pulled(v.Follows, comic)
+	})
+}
+
+// renderWeekly renders the weeks asked for by the query, keeping only the comics keep allows when it is set.
+func (s *Server) renderWeekly(w http.ResponseWriter, r *http.Request, title string, keep func(viewer, comicshelf.Comic) bool) {
+	var view weeklyView
+	var v viewer
+
+	g, ctx := errgroup.WithContext(r.Context())
+	g.Go(func() error {
+		resp, err := s.weekly(r.WithContext(ctx))
+		if err != nil {
+			return err
+		}
+
+		view = resp
+		return nil
+	})
+	g.Go(func() error {
+		resp, err := s.viewer(ctx)
+		if err != nil {
+			return err
+		}
+
+		v = resp
+		return nil
+	})
+
+	err := g.Wait()
 	if err != nil {
-		slog.Error(err.Error())
+		writeError(w, r, "could not get "+strings.ToLower(title), err)
 		return
 	}
 
-	comics, err := s.comics.GetWeeklyComics(r.Context(), t)
+	if keep != nil {
+		for i, week := range view.Weeks {
+			kept := make([]comicshelf.Comic, 0, len(week.Comics))
+			for _, comic := range week.Comics {
+				if keep(v, comic) {
+					kept = append(kept, comic)
+				}
+			}
+			view.Weeks[i].Comics = kept
+		}
+	}
+
+	content := View[weeklyView]{
+		Date:   r.URL.Query().Get("date"),
+		Title:  title,
+		Resp:   view,
+		Viewer: v,
+	}
+
+	err = s.render(w, r, s.comicTmpl, "index.html", content)
+	if err != nil {
+		slog.ErrorContext(r.Context(), err.Error())
+	}
+}
+
+// weekly fetches the weeks asked for by the query, either the single week containing date or every week from from to to.
+func (s *Server) weekly(r *http.Request) (weeklyView, error) {
+	query := r.URL.Query()
+
+	view := weeklyView{Release: query.Get("release")}
+	fetch := s.comics.GetWeeklyComics
+	switch view.Release {
+	case "", releaseUnlimited:
+		view.Release = releaseUnlimited
+	case releasePrint:
+		fetch = s.comics.GetReleasedComics
+	default:
+		return weeklyView{}, fmt.Errorf("unknown release %q: %w", view.Release, comicshelf.ErrInvalidInput)
+	}
+
+	filter, err := parseFilter(query)
+	if err != nil {
+		return weeklyView{}, err
+	}
+	view.Filter = filter
+
+	now := today(r.Context())
+	view.ThisWeek = weekQuery(releasePrint, now, filter)
+	view.NextWeek = weekQuery(releasePrint, now.AddDate(0, 0, 7), filter)
+	view.UnlimitedThisWeek = weekQuery(releaseUnlimited, now, filter)
+
+	from, to, err := weekBounds(query)
 	if err != nil {
-		slog.Error("getting series collection", slog.String("err", err.Error()))
+		return weeklyView{}, err
+	}
+
+	// checked before listing the weeks so a huge range is turned away without building it
+	if !to.Before(from.AddDate(0, 0, 7*maxRangeWeeks)) {
+		return weeklyView{}, fmt.Errorf("range covers more than %d weeks: %w", maxRangeWeeks, comicshelf.ErrInvalidInput)
+	}
+
+	weeks := make([]time.Time, 0, maxRangeWeeks)
+	for t := from; !t.After(to); t = t.AddDate(0, 0, 7) {
+		weeks = append(weeks, t)
+	}
+
+	view.Weeks = make([]weekView, len(weeks))
+	g, ctx := errgroup.WithContext(r.Context())
+	for i, t := range weeks {
+		i, t := i, t
+		g.Go(func() error {
+			comics, err := fetch(ctx, t)
+			if err != nil {
+				return err
+			}
+
+			week := weekView{Date: t.Format(justTheDateFormat)}
+			for _, comic := range comics.Results {
+				if filter.Matches(comic) {
+					week.Comics = append(week.Comics, comic)
+				}
+			}
+
+			view.Weeks[i] = week
+			return nil
+		})
+	}
+
+	err = g.Wait()
+	if err != nil {
+		return weeklyView{}, err
+	}
+
+	span := 7 * len(weeks)
+	if query.Has("from") {
+		view.From = from.Format(justTheDateFormat)
+		view.To = to.Format(justTheDateFormat)
+		view.Previous = rangeQuery(view.Release, from.AddDate(0, 0, -span), to.AddDate(0, 0, -span), filter)
+		view.Next = rangeQuery(view.Release, from.AddDate(0, 0, span), to.AddDate(0, 0, span), filter)
+		return view, nil
+	}
+
+	view.Previous = weekQuery(view.Release, from.AddDate(0, 0, -span), filter)
+	view.Next = weekQuery(view.Release, from.AddDate(0, 0, span), filter)
+
+	switch weekQuery(view.Release, from, filter) {
+	case view.ThisWeek:
+		view.Toggle = "this"
+	case view.NextWeek:
+		view.Toggle = "next"
+	case view.UnlimitedThisWeek:
+		view.Toggle = "unlimited"
+	}
+
+	return view, nil
+}
+
+func weekBounds(query url.Values) (time.Time, time.Time, error) {
+	if !query.Has("from") {
+		t, err := parseDay(query.Get("date"))
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("date query is not a valid date: %w", comicshelf.ErrInvalidInput)
+		}
+		return t, t, nil
+	}
+
+	from, err := parseDay(query.Get("from"))
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("from query is not a valid date: %w", comicshelf.ErrInvalidInput)
+	}
+
+	if !query.Has("to") {
+		return from, from, nil
+	}
+
+	to, err := parseDay(query.Get("to"))
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("to query is not a valid date: %w", comicshelf.ErrInvalidInput)
+	}
+
+	if to.Before(from) {
+		return time.Time{}, time.Time{}, fmt.Errorf("to is before from: %w", comicshelf.ErrInvalidInput)
+	}
+
+	return from, to, nil
+}
+
+func (s *Server) handleComic(w http.ResponseWriter, r *http.Request) {
+	comicId := chi.URLParam(r, "comicId")
+	id, err := strconv.Atoi(comicId)
+	if err != nil {
+		http.Error(w, "comic query is not a number", http.StatusUnprocessableEntity)
+		return
+	}
+
+	comic, err := s.comics.GetComic(r.Context(), id)
+	if err != nil {
+		writeError(w, r, fmt.Sprintf("could not get comic: %d", id), err)
+		return
+	}
+
+	view := comicView{Comic: comic}
+	var issues []comicshelf.Comic
+	var v viewer
+
+	g, ctx := errgroup.WithContext(r.Context())
+	g.Go(func() error {
+		resp, err := s.series.GetComicsWithinSeries(ctx, comic.SeriesId)
+		if err != nil {
+			return fmt.Errorf("could not get comics within series %d: %w", comic.SeriesId, err)
+		}
+
+		issues = resp
+		return nil
+	})
+	g.Go(func() error {
+		resp, err := s.lists.ReadingLists(ctx, 0) // using default user id until auth actually implemented
+		if err != nil {
+			return fmt.Errorf("could not get reading lists: %w", err)
+		}
+
+		view.Lists = resp
+		return nil
+	})
+	g.Go(func() error {
+		resp, err := s.viewer(ctx)
+		if err != nil {
+			return err
+		}
+
+		v = resp
+		return nil
+	})
+
+	err = g.Wait()
+	if err != nil {
+		writeError(w, r, fmt.Sprintf("could not get comic: %d", id), err)
 		return
 	}
 
-	content := View[comicshelf.Page[comicshelf.Comic]]{
-		Date:  r.URL.Query().Get("date"),
-		Title: "Weekly Comics",
-		Resp:  comics,
+	for i := range issues {
+		if issues[i].Id != comic.Id {
+			continue
+		}
+
+		if i > 0 {
+			view.Previous = &issues[i-1]
+		}
+
+		if i < len(issues)-1 {
+			view.Next = &issues[i+1]
+		}
+		break
+	}
+
+	content := View[comicView]{
+		Date:   r.URL.Query().Get("date"),
+		Title:  comic.Title,
+		Resp:   view,
+		Viewer: v,
+	}
+
+	err = s.render(w, r, s.comicDetailTmpl, "index.html", content)
+	if err != nil {
+		slog.ErrorContext(r.Context(), err.Error())
+	}
+}
+
+// releasesPage describes a page listing the issues around today for one creator or character.
+type releasesPage[T any] struct {
+	kind   string
+	param  string
+	tmpl   *page
+	get    func(ctx context.Context, id int) (T, error)
+	comics func(ctx context.Context, id int, from, to time.Time) ([]comicshelf.Comic, error)
+	title  func(T) string
+}
+
+func renderReleases[T any](s *Server, w http.ResponseWriter, r *http.Request, p releasesPage[T]) {
+	id, err := strconv.Atoi(chi.URLParam(r, p.param))
+	if err != nil {
+		http.Error(w, p.kind+" query is not a number", http.StatusUnprocessableEntity)
+		return
+	}
+
+	now := today(r.Context())
+	from, to := releaseWindow(now)
+
+	view := releasesView[T]{}
+	var comics []comicshelf.Comic
+	var v viewer
+
+	g, ctx := errgroup.WithContext(r.Context())
+	g.Go(func() error {
+		resp, err := p.get(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		view.Item = resp
+		return nil
+	})
+	g.Go(func() error {
+		resp, err := p.comics(ctx, id, from, to)
+		if err != nil {
+			return err
+		}
+
+		comics = resp
+		return nil
+	})
+	g.Go(func() error {
+		resp, err := s.viewer(ctx)
+		if err != nil {
+			return err
+		}
+
+		v = resp
+		return nil
+	})
+
+	err = g.Wait()
+	if err != nil {
+		writeError(w, r, fmt.Sprintf("could not get %s: %d", p.kind, id), err)
+		return
 	}
 
-	err = s.comicTmpl.ExecuteTemplate(w, "index.html", content)
+	view.Released, view.Upcoming = splitReleased(comics, now)
+
+	content := View[releasesView[T]]{
+		Date:   r.URL.Query().Get("date"),
+		Title:  p.title(view.Item),
+		Resp:   view,
+		Viewer: v,
+	}
+
+	err = s.render(w, r, p.tmpl, "index.html", content)
 	if err != nil {
-		slog.Error(err.Error())
+		slog.ErrorContext(r.Context(), err.Error())
+	}
+}
+
+func releaseWindow(t time.Time) (time.Time, time.Time) {
+	return t.AddDate(0, -releaseWindowMonths, 0), t.AddDate(0, releaseWindowMonths, 0)
+}
+
+func splitReleased(comics []comicshelf.Comic, t time.Time) ([]comicshelf.Comic, []comicshelf.Comic) {
+	var released, upcoming []comicshelf.Comic
+	for _, comic := range comics {
+		if comic.OnSaleDate.After(t) {
+			upcoming = append(upcoming, comic)
+			continue
+		}
+
+		released = append(released, comic)
 	}
+
+	return released, upcoming
 }
@@ -1,5 +1,58 @@
 package server
 
+import "time"
+
 type Config struct {
 	Address string `mapstructure:"address"`
+	// Timezone pages are rendered in until a viewer picks their own, UTC when unset.
+	Timezone *time.Location `mapstructure:"timezone"`
+	// Dev serves templates and static files from DevDir on disk, reloading them and any open pages on every change.
+	Dev    bool   `mapstructure:"dev"`
+	DevDir string `mapstructure:"dev_dir"`
+	// DrainTimeout is how long in-flight requests get to finish on shutdown, 10s when unset.
+	DrainTimeout time.Duration `mapstructure:"drain_timeout"`
+
+	// timeouts and limits passed straight to the http.Server, zero leaves them unbounded
+	ReadTimeout       time.Duration `mapstructure:"read_timeout"`
+	ReadHeaderTimeout time.Duration `mapstructure:"read_header_timeout"`
+	WriteTimeout      time.Duration `mapstructure:"write_timeout"`
+	IdleTimeout       time.Duration `mapstructure:"idle_timeout"`
+	MaxHeaderBytes    int           `mapstructure:"max_header_bytes"`
+
+	// CacheControl maps path prefixes to the Cache-Control sent for them, the longest matching prefix wins.
+	// Hashed static files are always cached for a year whatever is set here.
+	CacheControl map[string]string `mapstructure:"cache_control"`
+	// CompressionLevel applies to pages and api responses, static files are compressed ahead of time at the best level.
+	CompressionLevel int `mapstructure:"compression_level"`
+
+	TLS      TLSConfig      `mapstructure:"tls"`
+	HTTP2    HTTP2Config    `mapstructure:"http2"`
+	Security SecurityConfig `mapstructure:"security"`
+}
+
+// TLSConfig serves https when both files are set, the pair is reloaded whenever either file changes on disk.
+type TLSConfig struct {
+	CertFile string `mapstructure:"cert_file"`
+	KeyFile  string `mapstructure:"key_file"`
+}
+
+func (t TLSConfig) Enabled() bool {
+	return t.CertFile != "" && t.KeyFile != ""
+}
+
+// HTTP2Config only applies over TLS, plain http is always served as HTTP/1.1.
+type HTTP2Config struct {
+	Disabled             bool   `mapstructure:"disabled"`
+	MaxConcurrentStreams uint32 `mapstructure:"max_concurrent_streams"`
+	MaxReadFrameSize     uint32 `mapstructure:"max_read_frame_size"`
+}
+
+// SecurityConfig holds the response headers browsers use to lock pages down, empty values are not sent.
+type SecurityConfig struct {
+	ContentSecurityPolicy string `mapstructure:"content_security_policy"`
+	FrameOptions          string `mapstructure:"frame_options"`
+	ReferrerPolicy        string `mapstructure:"referrer_policy"`
+	// HSTSMaxAge is only sent over TLS, zero leaves it off.
+	HSTSMaxAge            time.Duration `mapstructure:"hsts_max_age"`
+	HSTSIncludeSubdomains bool          `mapstructure:"hsts_include_subdomains"`
 }
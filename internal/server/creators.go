@@ -0,0 +1,23 @@
+package server
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/jakedegiovanni/comicshelf"
+)
+
+func (s *Server) registerCreatorRoutes(router chi.Router) {
+	router.Get("/{creatorId}", s.handleCreator)
+}
+
+func (s *Server) handleCreator(w http.ResponseWriter, r *http.Request) {
+	renderReleases(s, w, r, releasesPage[comicshelf.Creator]{
+		kind:   "creator",
+		param:  "creatorId",
+		tmpl:   s.creatorTmpl,
+		get:    s.creators.GetCreator,
+		comics: s.creators.GetComicsByCreator,
+		title:  func(c comicshelf.Creator) string { return c.FullName },
+	})
+}
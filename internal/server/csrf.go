@@ -0,0 +1,132 @@
+package server
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"log/slog"
+	"net/http"
+	"sync"
+)
+
+const (
+	csrfCookie   = "csrf"
+	csrfHeader   = "X-CSRF-Token"
+	csrfField    = "csrf_token"
+	csrfTokenLen = 32
+)
+
+type csrfCtxKey struct{}
+
+// csrfState is the request's token. A new one is only sent as a cookie once a page asks for it,
+// so static files and probes stay free of Set-Cookie and can be cached.
+type csrfState struct {
+	token []byte
+	// w is set while the token still needs issuing
+	w      http.ResponseWriter
+	secure bool
+	issue  sync.Once
+}
+
+func (c *csrfState) issued() []byte {
+	c.issue.Do(func() {
+		if c.w == nil {
+			return
+		}
+
+		http.SetCookie(c.w, &http.Cookie{
+			Name:     csrfCookie,
+			Value:    base64.RawURLEncoding.EncodeToString(c.token),
+			Path:     "/",
+			HttpOnly: true,
+			Secure:   c.secure,
+			SameSite: http.SameSiteLaxMode,
+		})
+	})
+
+	return c.token
+}
+
+// csrf guards unsafe requests with a double submit token.
+// The token lives in a cookie another site cannot read, pages echo it back through the htmx header or a hidden form field.
+func csrf() func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			state := &csrfState{token: cookieToken(r)}
+			if state.token == nil {
+				state.token = make([]byte, csrfTokenLen)
+				_, _ = rand.Read(state.token)
+				state.w = w
+				state.secure = r.TLS != nil
+			}
+			token := state.token
+
+			r = r.WithContext(context.WithValue(r.Context(), csrfCtxKey{}, state))
+
+			switch r.Method {
+			case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace:
+			default:
+				sent := r.Header.Get(csrfHeader)
+				if sent == "" {
+					sent = r.PostFormValue(csrfField)
+				}
+
+				if subtle.ConstantTimeCompare(unmaskToken(sent), token) != 1 {
+					slog.WarnContext(r.Context(), "csrf token missing or invalid", slog.String("method", r.Method), slog.String("url", r.URL.String()))
+					http.Error(w, "invalid csrf token", http.StatusForbidden)
+					return
+				}
+			}
+
+			next.ServeHTTP(w, r)
+		}
+		return http.HandlerFunc(fn)
+	}
+}
+
+func cookieToken(r *http.Request) []byte {
+	cookie, err := r.Cookie(csrfCookie)
+	if err != nil {
+		return nil
+	}
+
+	token, err := base64.RawURLEncoding.DecodeString(cookie.Value)
+	if err != nil || len(token) != csrfTokenLen {
+		return nil
+	}
+
+	return token
+}
+
+// csrfToken is the token for the page being rendered, setting the cookie first if the browser has none yet.
+// It is masked with a fresh pad each time so compressed responses never repeat the same secret bytes.
+func csrfToken(ctx context.Context) string {
+	state, _ := ctx.Value(csrfCtxKey{}).(*csrfState)
+	if state == nil {
+		return ""
+	}
+	token := state.issued()
+
+	masked := make([]byte, 2*csrfTokenLen)
+	_, _ = rand.Read(masked[:csrfTokenLen])
+	for i, b := range token {
+		masked[csrfTokenLen+i] = masked[i] ^ b
+	}
+
+	return base64.RawURLEncoding.EncodeToString(masked)
+}
+
+func unmaskToken(s string) []byte {
+	masked, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil || len(masked) != 2*csrfTokenLen {
+		return nil
+	}
+
+	token := make([]byte, csrfTokenLen)
+	for i := range token {
+		token[i] = masked[i] ^ masked[csrfTokenLen+i]
+	}
+
+	return token
+}
@@ -0,0 +1,208 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func maskedToken(token []byte) string {
+	return csrfToken(context.WithValue(context.Background(), csrfCtxKey{}, &csrfState{token: token}))
+}
+
+func urlencoded(form url.Values) (string, *bytes.Buffer) {
+	return "application/x-www-form-urlencoded", bytes.NewBufferString(form.Encode())
+}
+
+func multipartForm(t *testing.T, fields map[string]string, file string) (string, *bytes.Buffer) {
+	t.Helper()
+
+	var body bytes.Buffer
+	w := multipart.NewWriter(&body)
+	for k, v := range fields {
+		require.NoError(t, w.WriteField(k, v))
+	}
+
+	part, err := w.CreateFormFile("file", "list.json")
+	require.NoError(t, err)
+	_, err = part.Write([]byte(file))
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	return w.FormDataContentType(), &body
+}
+
+func TestCSRF(t *testing.T) {
+	token := bytes.Repeat([]byte{7}, csrfTokenLen)
+	cookie := &http.Cookie{Name: csrfCookie, Value: base64.RawURLEncoding.EncodeToString(token)}
+
+	tampered := []byte(maskedToken(token))
+	tampered[10] ^= 'A' ^ 'B'
+
+	list := `{"name": "Imported", "comics": [{"id": 1}]}`
+
+	tests := []struct {
+		name   string
+		method string
+		target string
+		header string
+		body   func(t *testing.T) (string, *bytes.Buffer)
+		status int
+	}{
+		{
+			name:   "get is exempt",
+			method: http.MethodGet,
+			target: "/notifications",
+			status: http.StatusOK,
+		},
+		{
+			name:   "post without token",
+			method: http.MethodPost,
+			target: "/notifications/seen",
+			status: http.StatusForbidden,
+		},
+		{
+			name:   "header token",
+			method: http.MethodPost,
+			target: "/notifications/seen",
+			header: maskedToken(token),
+			status: http.StatusSeeOther,
+		},
+		{
+			name:   "form field",
+			method: http.MethodPost,
+			target: "/notifications/seen",
+			body: func(t *testing.T) (string, *bytes.Buffer) {
+				return urlencoded(url.Values{csrfField: {maskedToken(token)}})
+			},
+			status: http.StatusSeeOther,
+		},
+		{
+			name:   "multipart form field",
+			method: http.MethodPost,
+			target: "/lists/import",
+			body: func(t *testing.T) (string, *bytes.Buffer) {
+				return multipartForm(t, map[string]string{csrfField: maskedToken(token)}, list)
+			},
+			status: http.StatusSeeOther,
+		},
+		{
+			name:   "multipart without token",
+			method: http.MethodPost,
+			target: "/lists/import",
+			body: func(t *testing.T) (string, *bytes.Buffer) {
+				return multipartForm(t, nil, list)
+			},
+			status: http.StatusForbidden,
+		},
+		{
+			name:   "tampered mask",
+			method: http.MethodPost,
+			target: "/notifications/seen",
+			header: string(tampered),
+			status: http.StatusForbidden,
+		},
+		{
+			name:   "unmasked cookie value",
+			method: http.MethodPost,
+			target: "/notifications/seen",
+			header: cookie.Value,
+			status: http.StatusForbidden,
+		},
+	}
+
+	s := newTestServer(t, &Config{}, &fakeCatalogue{})
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.target, nil)
+			if tt.body != nil {
+				contentType, body := tt.body(t)
+				req = httptest.NewRequest(tt.method, tt.target, body)
+				req.Header.Set("Content-Type", contentType)
+			}
+
+			req.AddCookie(cookie)
+			if tt.header != "" {
+				req.Header.Set(csrfHeader, tt.header)
+			}
+
+			rec := httptest.NewRecorder()
+			s.srv.Handler.ServeHTTP(rec, req)
+			assert.Equal(t, tt.status, rec.Code, rec.Body.String())
+		})
+	}
+}
+
+func TestCSRFCookie(t *testing.T) {
+	s := newTestServer(t, &Config{}, &fakeCatalogue{})
+
+	rec := get(t, s, "/notifications")
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var cookie *http.Cookie
+	for _, c := range rec.Result().Cookies() {
+		if c.Name == csrfCookie {
+			cookie = c
+		}
+	}
+	require.NotNil(t, cookie, "first visit sets the csrf cookie")
+	assert.True(t, cookie.HttpOnly)
+	assert.Equal(t, http.SameSiteLaxMode, cookie.SameSite)
+	assert.Equal(t, "/", cookie.Path)
+
+	// the page carries a masked copy of the cookie's token, never the token itself
+	token, err := base64.RawURLEncoding.DecodeString(cookie.Value)
+	require.NoError(t, err)
+	assert.NotContains(t, rec.Body.String(), cookie.Value)
+	assert.Contains(t, rec.Body.String(), csrfField)
+
+	req := httptest.NewRequest(http.MethodGet, "/notifications", nil)
+	req.AddCookie(cookie)
+	rec = httptest.NewRecorder()
+	s.srv.Handler.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Empty(t, rec.Result().Cookies(), "a valid cookie is kept")
+
+	sent := rec.Body.String()
+	i := strings.Index(sent, `name="csrf_token" value="`)
+	require.NotEqual(t, -1, i)
+	masked, _, _ := strings.Cut(sent[i+len(`name="csrf_token" value="`):], `"`)
+	assert.Equal(t, token, unmaskToken(masked))
+}
+
+func TestCSRFCookieOnlyOnPages(t *testing.T) {
+	s := newTestServer(t, &Config{}, &fakeCatalogue{})
+
+	tests := []struct {
+		target string
+		cookie bool
+	}{
+		{target: "/notifications", cookie: true},
+		{target: "/static/index.css"},
+		{target: "/metrics"},
+		{target: "/livez"},
+		{target: "/readyz"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.target, func(t *testing.T) {
+			rec := get(t, s, tt.target)
+			require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+			var set bool
+			for _, c := range rec.Result().Cookies() {
+				set = set || c.Name == csrfCookie
+			}
+			assert.Equal(t, tt.cookie, set)
+		})
+	}
+}
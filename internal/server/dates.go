@@ -0,0 +1,81 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+const (
+	justTheDateFormat = "2006-01-02"
+
+	// tzCookie remembers the timezone a viewer asked for with ?tz= so every page after renders in it.
+	tzCookie = "tz"
+)
+
+type zoneCtxKey struct{}
+
+// viewerZone resolves the timezone the request is rendered in, from ?tz=, then the cookie, then the fallback.
+// An unknown zone is ignored rather than failing the page.
+func viewerZone(fallback *time.Location) func(http.Handler) http.Handler {
+	if fallback == nil {
+		fallback = time.UTC
+	}
+
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			loc := fallback
+
+			if cookie, err := r.Cookie(tzCookie); err == nil {
+				if l, err := time.LoadLocation(cookie.Value); err == nil {
+					loc = l
+				}
+			}
+
+			if tz := r.URL.Query().Get("tz"); tz != "" {
+				if l, err := time.LoadLocation(tz); err == nil {
+					loc = l
+					http.SetCookie(w, &http.Cookie{
+						Name:     tzCookie,
+						Value:    l.String(),
+						Path:     "/",
+						MaxAge:   int((365 * 24 * time.Hour).Seconds()),
+						SameSite: http.SameSiteLaxMode,
+					})
+				}
+			}
+
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), zoneCtxKey{}, loc)))
+		}
+		return http.HandlerFunc(fn)
+	}
+}
+
+func zone(ctx context.Context) *time.Location {
+	loc, ok := ctx.Value(zoneCtxKey{}).(*time.Location)
+	if !ok {
+		return time.UTC
+	}
+
+	return loc
+}
+
+// calendarDay is the date t falls on, as noon UTC. Read in a provider's timezone it keeps that date from UTC-12 up to
+// but not including UTC+12, a provider in a zone such as UTC+13 would see the next day.
+func calendarDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 12, 0, 0, 0, time.UTC)
+}
+
+// today is the viewer's current date.
+func today(ctx context.Context) time.Time {
+	return calendarDay(time.Now().In(zone(ctx)))
+}
+
+func parseDay(s string) (time.Time, error) {
+	t, err := time.Parse(justTheDateFormat, s)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	return calendarDay(t), nil
+}
@@ -0,0 +1,176 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+const liveReloadPath = "/dev/reload"
+
+// devReloadSettle is how long files must go unchanged before reloading, editors often save in several writes.
+const devReloadSettle = 100 * time.Millisecond
+
+// liveReload tells the browsers of dev mode pages to refresh once templates or static files have been reloaded.
+type liveReload struct {
+	mu      sync.Mutex
+	clients map[chan struct{}]struct{}
+	done    chan struct{}
+	closed  bool
+}
+
+func newLiveReload() *liveReload {
+	return &liveReload{
+		clients: make(map[chan struct{}]struct{}),
+		done:    make(chan struct{}),
+	}
+}
+
+func (l *liveReload) subscribe() chan struct{} {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	ch := make(chan struct{}, 1)
+	l.clients[ch] = struct{}{}
+	return ch
+}
+
+func (l *liveReload) unsubscribe(ch chan struct{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.clients, ch)
+}
+
+func (l *liveReload) notify() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for ch := range l.clients {
+		select {
+		case ch <- struct{}{}:
+		default: // already has a reload waiting
+		}
+	}
+}
+
+// close ends every open stream, otherwise they would hold up draining the server until it times out.
+func (l *liveReload) close() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if !l.closed {
+		l.closed = true
+		close(l.done)
+	}
+}
+
+func (s *Server) handleLiveReload(w http.ResponseWriter, r *http.Request) {
+	rc := http.NewResponseController(w)
+
+	// the stream stays open far longer than the server's write timeout allows a response
+	err := rc.SetWriteDeadline(time.Time{})
+	if err != nil && !errors.Is(err, http.ErrNotSupported) {
+		slog.WarnContext(r.Context(), "could not lift write deadline for live reload", slog.String("err", err.Error()))
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(http.StatusOK)
+
+	err = rc.Flush()
+	if err != nil {
+		slog.WarnContext(r.Context(), "live reload stream cannot be flushed", slog.String("err", err.Error()))
+		return
+	}
+
+	ch := s.live.subscribe()
+	defer s.live.unsubscribe(ch)
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-s.live.done:
+			return
+		case <-ch:
+			_, err = fmt.Fprint(w, "event: reload\ndata: {}\n\n")
+			if err == nil {
+				err = rc.Flush()
+			}
+
+			if err != nil {
+				return
+			}
+		}
+	}
+}
+
+// watchDev reloads templates and static files from disk whenever they change until ctx is done.
+// A file that fails to parse is logged and the previous version kept, so a half finished edit does not take pages down.
+func (s *Server) watchDev(ctx context.Context) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("could not watch dev files: %w", err)
+	}
+	defer watcher.Close()
+
+	// fsnotify does not recurse, so every directory is watched on its own
+	for _, dir := range []string{"templates", "static"} {
+		root := filepath.Join(s.cfg.DevDir, dir)
+		err = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+			if err != nil || !d.IsDir() {
+				return err
+			}
+
+			return watcher.Add(path)
+		})
+		if err != nil {
+			return fmt.Errorf("could not watch %s: %w", root, err)
+		}
+	}
+
+	slog.Info("dev mode watching for changes", slog.String("dir", s.cfg.DevDir))
+
+	settle := time.NewTimer(0)
+	<-settle.C
+	defer settle.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case err := <-watcher.Errors:
+			slog.Warn("dev watch error", slog.String("err", err.Error()))
+		case event := <-watcher.Events:
+			if event.Has(fsnotify.Chmod) {
+				continue
+			}
+
+			if event.Has(fsnotify.Create) {
+				if info, err := os.Stat(event.Name); err == nil && info.IsDir() {
+					_ = watcher.Add(event.Name)
+				}
+			}
+
+			settle.Reset(devReloadSettle)
+		case <-settle.C:
+			err := errors.Join(s.assets.load(), s.loadTemplates())
+			if err != nil {
+				slog.Error("could not reload dev files", slog.String("err", err.Error()))
+				continue
+			}
+
+			slog.Info("reloaded templates and static files")
+			s.live.notify()
+		}
+	}
+}
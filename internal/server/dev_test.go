@@ -0,0 +1,78 @@
+package server
+
+import (
+	"context"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// copyEmbedded writes the embedded templates and static files into dir, laid out as DevDir expects.
+func copyEmbedded(t *testing.T, dir string) {
+	t.Helper()
+
+	for root, fsys := range map[string]fs.FS{"templates": templates, "static": static} {
+		err := fs.WalkDir(fsys, root, func(name string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+
+			target := filepath.Join(dir, filepath.FromSlash(name))
+			if d.IsDir() {
+				return os.MkdirAll(target, 0755)
+			}
+
+			content, err := fs.ReadFile(fsys, name)
+			if err != nil {
+				return err
+			}
+
+			return os.WriteFile(target, content, 0644)
+		})
+		require.NoError(t, err)
+	}
+}
+
+func TestWatchDevReloadsTemplates(t *testing.T) {
+	dir := t.TempDir()
+	copyEmbedded(t, dir)
+
+	s := newTestServer(t, &Config{Dev: true, DevDir: dir}, &fakeCatalogue{})
+	assert.NotContains(t, get(t, s, "/notifications").Body.String(), "edited in dev")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- s.watchDev(ctx)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		require.NoError(t, <-done)
+	})
+
+	reload := s.live.subscribe()
+	defer s.live.unsubscribe(reload)
+
+	// give the watcher time to start before editing underneath it
+	time.Sleep(100 * time.Millisecond)
+
+	name := filepath.Join(dir, "templates", "notifications", "content.html")
+	content, err := os.ReadFile(name)
+	require.NoError(t, err)
+	edited := strings.Replace(string(content), "Nothing new", "Nothing new, edited in dev", 1)
+	require.NoError(t, os.WriteFile(name, []byte(edited), 0644))
+
+	select {
+	case <-reload:
+	case <-time.After(5 * time.Second):
+		t.Fatal("no reload sent after editing a template")
+	}
+
+	assert.Contains(t, get(t, s, "/notifications").Body.String(), "edited in dev")
+}
@@ -0,0 +1,30 @@
+package server
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/jakedegiovanni/comicshelf"
+)
+
+func errorStatus(err error) int {
+	switch {
+	case errors.Is(err, comicshelf.ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, comicshelf.ErrInvalidInput):
+		return http.StatusBadRequest
+	case errors.Is(err, comicshelf.ErrConflict):
+		return http.StatusConflict
+	case errors.Is(err, comicshelf.ErrUpstream):
+		return http.StatusBadGateway
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+func writeError(w http.ResponseWriter, r *http.Request, msg string, err error) {
+	status := errorStatus(err)
+	slog.ErrorContext(r.Context(), msg, slog.String("err", err.Error()), slog.Int("status", status))
+	http.Error(w, msg, status)
+}
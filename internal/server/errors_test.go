@@ -0,0 +1,33 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/jakedegiovanni/comicshelf"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestErrorStatus(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{name: "not found", err: comicshelf.ErrNotFound, want: http.StatusNotFound},
+		{name: "invalid input", err: comicshelf.ErrInvalidInput, want: http.StatusBadRequest},
+		{name: "conflict", err: comicshelf.ErrConflict, want: http.StatusConflict},
+		{name: "upstream", err: comicshelf.ErrUpstream, want: http.StatusBadGateway},
+		{name: "wrapped", err: fmt.Errorf("could not load comic: %w", comicshelf.ErrNotFound), want: http.StatusNotFound},
+		{name: "joined", err: errors.Join(errors.New("timeout"), comicshelf.ErrUpstream), want: http.StatusBadGateway},
+		{name: "unknown", err: errors.New("boom"), want: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, errorStatus(tt.err))
+		})
+	}
+}
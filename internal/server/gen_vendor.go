@@ -0,0 +1,67 @@
+//go:build ignore
+
+// gen_vendor downloads the third party browser dependencies into static/vendor and checks each against its pinned hash.
+// The files are committed, so this only needs running when bumping a version.
+package main
+
+import (
+	"crypto/sha512"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+)
+
+var deps = []struct {
+	url       string
+	file      string
+	integrity string
+}{
+	{
+		url:       "https://unpkg.com/htmx.org@1.9.4/dist/htmx.min.js",
+		file:      "static/vendor/htmx.min.js",
+		integrity: "sha384-zUfuhFKKZCbHTY6aRR46gxiqszMk5tcHjsVFxnUo8VMus4kHGVdIYVbOYYNlKmHV",
+	},
+}
+
+func main() {
+	for _, dep := range deps {
+		err := fetch(dep.url, dep.file, dep.integrity)
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+}
+
+func fetch(url, file, integrity string) error {
+	resp, err := http.Get(url)
+	if err != nil {
+		return fmt.Errorf("could not download %s: %w", url, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("could not download %s: %s", url, resp.Status)
+	}
+
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("could not read %s: %w", url, err)
+	}
+
+	sum := sha512.Sum384(b)
+	got := "sha384-" + base64.StdEncoding.EncodeToString(sum[:])
+	if got != integrity {
+		return fmt.Errorf("%s does not match its pinned hash, got %s want %s", url, got, integrity)
+	}
+
+	err = os.MkdirAll(filepath.Dir(file), 0o755)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(file, b, 0o644)
+}
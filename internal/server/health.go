@@ -0,0 +1,96 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+// checkTimeout bounds each readiness check so one hung dependency can't hold up the probe.
+const checkTimeout = 2 * time.Second
+
+// Check reports whether a dependency is ready to serve, nil when it is.
+type Check func(ctx context.Context) error
+
+type checkResult struct {
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+type healthView struct {
+	Status string                 `json:"status"`
+	Checks map[string]checkResult `json:"checks,omitempty"`
+}
+
+// AddCheck adds a dependency to the readiness probe, it must be called before Run.
+func (s *Server) AddCheck(name string, check Check) {
+	s.checks[name] = check
+}
+
+func (s *Server) handleLive(w http.ResponseWriter, r *http.Request) {
+	writeHealth(w, r, http.StatusOK, healthView{Status: "ok"})
+}
+
+// handleReady runs every check at once. A check still running at checkTimeout is reported as failed without waiting on
+// it, since only checks that watch their context stop when it ends.
+func (s *Server) handleReady(w http.ResponseWriter, r *http.Request) {
+	view := healthView{Status: "ok", Checks: make(map[string]checkResult, len(s.checks))}
+	status := http.StatusOK
+
+	ctx, cancel := context.WithTimeout(r.Context(), checkTimeout)
+	defer cancel()
+
+	type namedResult struct {
+		name   string
+		result checkResult
+	}
+
+	// buffered so a check finishing after the probe has answered does not block forever
+	results := make(chan namedResult, len(s.checks))
+	for name, check := range s.checks {
+		name, check := name, check
+		go func() {
+			result := checkResult{Status: "ok"}
+			if err := check(ctx); err != nil {
+				result = checkResult{Status: "failed", Error: err.Error()}
+			}
+			results <- namedResult{name: name, result: result}
+		}()
+	}
+
+wait:
+	for range s.checks {
+		select {
+		case res := <-results:
+			view.Checks[res.name] = res.result
+		case <-ctx.Done():
+			break wait
+		}
+	}
+
+	for name := range s.checks {
+		if _, ok := view.Checks[name]; !ok {
+			view.Checks[name] = checkResult{Status: "failed", Error: "check did not finish in time"}
+		}
+
+		if view.Checks[name].Error != "" {
+			view.Status = "unavailable"
+			status = http.StatusServiceUnavailable
+		}
+	}
+
+	writeHealth(w, r, status, view)
+}
+
+func writeHealth(w http.ResponseWriter, r *http.Request, status int, view healthView) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+
+	err := json.NewEncoder(w).Encode(view)
+	if err != nil {
+		slog.WarnContext(r.Context(), "error writing health", slog.String("err", err.Error()))
+	}
+}
@@ -0,0 +1,74 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReady(t *testing.T) {
+	stuck := make(chan struct{})
+	t.Cleanup(func() { close(stuck) })
+
+	ok := func(ctx context.Context) error { return nil }
+	failing := func(ctx context.Context) error { return errors.New("down") }
+	// ignores its context, as a check blocked somewhere it cannot be interrupted would
+	hung := func(ctx context.Context) error {
+		<-stuck
+		return nil
+	}
+
+	tests := []struct {
+		name   string
+		checks map[string]Check
+		status int
+		want   map[string]checkResult
+	}{
+		{
+			name:   "no checks",
+			status: http.StatusOK,
+		},
+		{
+			name:   "all ready",
+			checks: map[string]Check{"db": ok, "marvel": ok},
+			status: http.StatusOK,
+			want:   map[string]checkResult{"db": {Status: "ok"}, "marvel": {Status: "ok"}},
+		},
+		{
+			name:   "one failing",
+			checks: map[string]Check{"db": ok, "marvel": failing},
+			status: http.StatusServiceUnavailable,
+			want:   map[string]checkResult{"db": {Status: "ok"}, "marvel": {Status: "failed", Error: "down"}},
+		},
+		{
+			name:   "one hung",
+			checks: map[string]Check{"db": ok, "marvel": hung},
+			status: http.StatusServiceUnavailable,
+			want:   map[string]checkResult{"db": {Status: "ok"}, "marvel": {Status: "failed", Error: "check did not finish in time"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestServer(t, &Config{}, &fakeCatalogue{})
+			for name, check := range tt.checks {
+				s.AddCheck(name, check)
+			}
+
+			start := time.Now()
+			rec := get(t, s, "/readyz")
+			assert.Less(t, time.Since(start), 2*checkTimeout)
+			require.Equal(t, tt.status, rec.Code)
+
+			var view healthView
+			require.NoError(t, json.NewDecoder(rec.Body).Decode(&view))
+			assert.Equal(t, tt.want, view.Checks)
+		})
+	}
+}
@@ -1,20 +1,194 @@
 package server
 
 import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
 	"log/slog"
 	"net/http"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
+
+	"github.com/andybalholm/brotli"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/jakedegiovanni/comicshelf/internal/logging"
+	"github.com/jakedegiovanni/comicshelf/internal/metrics"
+	"github.com/jakedegiovanni/comicshelf/internal/tracing"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
 )
 
+const requestIdHeader = "X-Request-Id"
+
+// requestId tags the request with the caller's id, or a new one, so every log line for it can be tied together.
+func requestId() func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			id := r.Header.Get(requestIdHeader)
+			if id == "" || len(id) > 64 {
+				b := make([]byte, 8)
+				_, _ = rand.Read(b)
+				id = hex.EncodeToString(b)
+			}
+
+			w.Header().Set(requestIdHeader, id)
+			next.ServeHTTP(w, r.WithContext(logging.WithRequestId(r.Context(), id)))
+		}
+		return http.HandlerFunc(fn)
+	}
+}
+
+// securityHeaders sets the browser hardening headers, any left empty in config are not sent.
+func securityHeaders(cfg *SecurityConfig) func(http.Handler) http.Handler {
+	hsts := ""
+	if cfg.HSTSMaxAge > 0 {
+		hsts = fmt.Sprintf("max-age=%d", int(cfg.HSTSMaxAge.Seconds()))
+		if cfg.HSTSIncludeSubdomains {
+			hsts += "; includeSubDomains"
+		}
+	}
+
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			h := w.Header()
+			h.Set("X-Content-Type-Options", "nosniff")
+
+			if cfg.ContentSecurityPolicy != "" {
+				h.Set("Content-Security-Policy", cfg.ContentSecurityPolicy)
+			}
+
+			if cfg.FrameOptions != "" {
+				h.Set("X-Frame-Options", cfg.FrameOptions)
+			}
+
+			if cfg.ReferrerPolicy != "" {
+				h.Set("Referrer-Policy", cfg.ReferrerPolicy)
+			}
+
+			// browsers ignore hsts over plain http, so only send it where it means something
+			if hsts != "" && r.TLS != nil {
+				h.Set("Strict-Transport-Security", hsts)
+			}
+
+			next.ServeHTTP(w, r)
+		}
+		return http.HandlerFunc(fn)
+	}
+}
+
+// compress compresses the rendered pages and api responses, static files arrive already compressed and are passed through.
+func compress(level int) func(http.Handler) http.Handler {
+	c := middleware.NewCompressor(level, "text/html", "text/plain", "text/css", "text/javascript", "application/javascript", "application/json", "image/svg+xml")
+	c.SetEncoder("br", func(w io.Writer, level int) io.Writer {
+		return brotli.NewWriterLevel(w, level)
+	})
+
+	return c.Handler
+}
+
+// cacheControl sets the policy for the longest path prefix matching the request, a handler can still override it.
+func cacheControl(rules map[string]string) func(http.Handler) http.Handler {
+	prefixes := make([]string, 0, len(rules))
+	for prefix := range rules {
+		prefixes = append(prefixes, prefix)
+	}
+	sort.Slice(prefixes, func(i, j int) bool {
+		return len(prefixes[i]) > len(prefixes[j])
+	})
+
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			for _, prefix := range prefixes {
+				if strings.HasPrefix(r.URL.Path, prefix) {
+					w.Header().Set("Cache-Control", rules[prefix])
+					break
+				}
+			}
+
+			next.ServeHTTP(w, r)
+		}
+		return http.HandlerFunc(fn)
+	}
+}
+
 func serverLogger() func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		fn := func(w http.ResponseWriter, r *http.Request) {
-			method := r.Method
-			url := r.URL.String()
+			ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+			start := time.Now()
+
+			defer func() {
+				slog.InfoContext(r.Context(), "request",
+					slog.String("method", r.Method),
+					slog.String("url", r.URL.String()),
+					slog.Int("status", ww.Status()),
+					slog.Int("bytes", ww.BytesWritten()),
+					slog.Duration("duration", time.Since(start)),
+					slog.String("remote_addr", r.RemoteAddr),
+				)
+			}()
+
+			next.ServeHTTP(ww, r)
+		}
+		return http.HandlerFunc(fn)
+	}
+}
+
+// serverMetrics times each request under its route pattern rather than its path, keeping the label set bounded.
+func serverMetrics() func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+			start := time.Now()
 
-			slog.Info(url, slog.String("method", method))
+			next.ServeHTTP(ww, r)
+
+			route := chi.RouteContext(r.Context()).RoutePattern()
+			if route == "" {
+				route = "unmatched"
+			}
 
-			next.ServeHTTP(w, r) // todo log response code
+			metrics.HTTPRequests.
+				WithLabelValues(r.Method, route, strconv.Itoa(ww.Status())).
+				Observe(time.Since(start).Seconds())
+		}
+		return http.HandlerFunc(fn)
+	}
+}
+
+// serverTracing spans every request, continuing any trace the caller started, and names it after the matched route.
+func serverTracing() func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			ctx := otel.GetTextMapPropagator().Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+			ctx, span := tracing.Tracer().Start(ctx, "HTTP "+r.Method,
+				trace.WithSpanKind(trace.SpanKindServer),
+				trace.WithAttributes(
+					semconv.HTTPRequestMethodKey.String(r.Method),
+					semconv.URLPath(r.URL.Path),
+				),
+			)
+			defer span.End()
+
+			ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+			next.ServeHTTP(ww, r.WithContext(ctx))
+
+			if route := chi.RouteContext(ctx).RoutePattern(); route != "" {
+				span.SetName("HTTP " + r.Method + " " + route)
+				span.SetAttributes(semconv.HTTPRoute(route))
+			}
+
+			span.SetAttributes(semconv.HTTPResponseStatusCode(ww.Status()))
+			if ww.Status() >= http.StatusInternalServerError {
+				span.SetStatus(codes.Error, http.StatusText(ww.Status()))
+			}
 		}
 		return http.HandlerFunc(fn)
 	}
@@ -23,11 +197,11 @@ func serverLogger() func(http.Handler) http.Handler {
 func queryDate() func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		fn := func(w http.ResponseWriter, r *http.Request) {
-			if !r.URL.Query().Has("date") {
-				slog.Debug("no date found in query, setting and redirecting")
+			if !r.URL.Query().Has("date") && !r.URL.Query().Has("from") {
+				slog.DebugContext(r.Context(), "no date found in query, setting and redirecting")
 
 				query := r.URL.Query()
-				query.Set("date", time.Now().Format(justTheDateFormat))
+				query.Set("date", today(r.Context()).Format(justTheDateFormat))
 				r.URL.RawQuery = query.Encode()
 
 				http.Redirect(w, r, r.URL.String(), http.StatusFound)
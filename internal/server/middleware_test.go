@@ -0,0 +1,201 @@
+package server
+
+import (
+	"context"
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/jakedegiovanni/comicshelf"
+	"github.com/jakedegiovanni/comicshelf/internal/filedb"
+	"github.com/jakedegiovanni/comicshelf/internal/tracing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestSecurityHeaders(t *testing.T) {
+	full := &SecurityConfig{
+		ContentSecurityPolicy: "default-src 'self'",
+		FrameOptions:          "DENY",
+		ReferrerPolicy:        "strict-origin-when-cross-origin",
+		HSTSMaxAge:            365 * 24 * time.Hour,
+		HSTSIncludeSubdomains: true,
+	}
+
+	tests := []struct {
+		name string
+		cfg  *SecurityConfig
+		tls  bool
+		want map[string]string
+	}{
+		{
+			name: "configured over tls",
+			cfg:  full,
+			tls:  true,
+			want: map[string]string{
+				"X-Content-Type-Options":    "nosniff",
+				"Content-Security-Policy":   "default-src 'self'",
+				"X-Frame-Options":           "DENY",
+				"Referrer-Policy":           "strict-origin-when-cross-origin",
+				"Strict-Transport-Security": "max-age=31536000; includeSubDomains",
+			},
+		},
+		{
+			name: "no hsts over plain http",
+			cfg:  full,
+			want: map[string]string{
+				"X-Content-Type-Options":    "nosniff",
+				"Content-Security-Policy":   "default-src 'self'",
+				"Strict-Transport-Security": "",
+			},
+		},
+		{
+			name: "hsts without subdomains",
+			cfg:  &SecurityConfig{HSTSMaxAge: time.Hour},
+			tls:  true,
+			want: map[string]string{
+				"Strict-Transport-Security": "max-age=3600",
+			},
+		},
+		{
+			name: "unset leaves only nosniff",
+			cfg:  &SecurityConfig{},
+			tls:  true,
+			want: map[string]string{
+				"X-Content-Type-Options":    "nosniff",
+				"Content-Security-Policy":   "",
+				"X-Frame-Options":           "",
+				"Referrer-Policy":           "",
+				"Strict-Transport-Security": "",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.tls {
+				req.TLS = &tls.ConnectionState{}
+			}
+
+			rec := httptest.NewRecorder()
+			securityHeaders(tt.cfg)(http.NotFoundHandler()).ServeHTTP(rec, req)
+
+			for header, want := range tt.want {
+				assert.Equal(t, want, rec.Header().Get(header), header)
+			}
+		})
+	}
+}
+
+func TestCacheControl(t *testing.T) {
+	rules := map[string]string{
+		"/":         "no-cache",
+		"/api/":     "no-store",
+		"/api/pub/": "public, max-age=60",
+		"/metrics":  "no-store",
+	}
+
+	tests := []struct {
+		path string
+		want string
+	}{
+		{path: "/", want: "no-cache"},
+		{path: "/comics", want: "no-cache"},
+		{path: "/api/follow", want: "no-store"},
+		{path: "/api/pub/feed", want: "public, max-age=60"},
+		{path: "/api", want: "no-cache"},
+		{path: "/metrics", want: "no-store"},
+	}
+
+	handler := cacheControl(rules)(http.NotFoundHandler())
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.path, nil))
+			assert.Equal(t, tt.want, rec.Header().Get("Cache-Control"))
+		})
+	}
+
+	// no rules leaves the header to the handler
+	rec := httptest.NewRecorder()
+	cacheControl(nil)(http.NotFoundHandler()).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Empty(t, rec.Header().Get("Cache-Control"))
+}
+
+// recordSpans installs a global provider whose spans are kept for the test to inspect.
+func recordSpans(t *testing.T) *tracetest.SpanRecorder {
+	t.Helper()
+
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	return recorder
+}
+
+func TestServerTracing(t *testing.T) {
+	recorder := recordSpans(t)
+
+	router := chi.NewRouter()
+	router.Use(serverTracing())
+	router.Get("/comics/{comicId}", func(w http.ResponseWriter, r *http.Request) {
+		_, span := tracing.Start(r.Context(), "handler")
+		span.End()
+		w.WriteHeader(http.StatusBadGateway)
+	})
+
+	traceId := "4bf92f3577b34da6a3ce929d0e0e4736"
+	req := httptest.NewRequest(http.MethodGet, "/comics/1", nil)
+	req.Header.Set("traceparent", "00-"+traceId+"-00f067aa0ba902b7-01")
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 2)
+	handler, server := spans[0], spans[1]
+
+	assert.Equal(t, "HTTP GET /comics/{comicId}", server.Name())
+	assert.Equal(t, trace.SpanKindServer, server.SpanKind())
+	assert.Equal(t, traceId, server.SpanContext().TraceID().String())
+	assert.True(t, server.Parent().IsRemote())
+	assert.Equal(t, codes.Error, server.Status().Code)
+
+	assert.Equal(t, server.SpanContext().SpanID(), handler.Parent().SpanID())
+}
+
+func TestPageSpansShareRequestTrace(t *testing.T) {
+	recorder := recordSpans(t)
+
+	db, err := filedb.New(&filedb.Config{Filename: filepath.Join(t.TempDir(), "db.json")})
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		require.NoError(t, db.Shutdown(context.Background()))
+	})
+
+	catalogue := &fakeCatalogue{comics: []comicshelf.Comic{{Id: 1, SeriesId: 2, Title: "Issue"}}}
+	s, err := New(&Config{}, catalogue, catalogue, catalogue, catalogue, catalogue, tracing.NewUserService(db), db)
+	require.NoError(t, err)
+
+	for _, target := range []string{"/creators/1", "/characters/1", "/comics/pull?date=2023-08-02"} {
+		rec := get(t, s, target)
+		require.Equal(t, http.StatusOK, rec.Code, target)
+	}
+
+	var roots int
+	for _, span := range recorder.Ended() {
+		if !span.Parent().IsValid() {
+			roots++
+			assert.Equal(t, trace.SpanKindServer, span.SpanKind(), "%s is an orphan", span.Name())
+		}
+	}
+	assert.Equal(t, 3, roots)
+}
@@ -0,0 +1,239 @@
+package server
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/jakedegiovanni/comicshelf"
+	"golang.org/x/sync/errgroup"
+)
+
+// notificationWeeks is how far back notifications look when the user has not checked them in a while.
+const notificationWeeks = 4
+
+// releasedWeeksTTL is how long a week's releases are reused for notifications. The count is loaded on every page, so
+// without it each page view would ask the upstream for every week again.
+const releasedWeeksTTL = 15 * time.Minute
+
+type releasedWeek struct {
+	comics  []comicshelf.Comic
+	expires time.Time
+}
+
+// releasedWeeks keeps the comics released each week for a while. They are the same for every user, it is only which
+// of them a user follows that differs, so follows and the seen time are still read fresh on every request.
+type releasedWeeks struct {
+	ttl   time.Duration
+	mu    sync.Mutex
+	weeks map[string]releasedWeek
+}
+
+func newReleasedWeeks(ttl time.Duration) *releasedWeeks {
+	return &releasedWeeks{ttl: ttl, weeks: make(map[string]releasedWeek)}
+}
+
+func (r *releasedWeeks) get(ctx context.Context, t time.Time, fetch func(context.Context, time.Time) (comicshelf.Page[comicshelf.Comic], error)) ([]comicshelf.Comic, error) {
+	key := t.Format(justTheDateFormat)
+	now := time.Now()
+
+	r.mu.Lock()
+	week, ok := r.weeks[key]
+	r.mu.Unlock()
+	if ok && now.Before(week.expires) {
+		return week.comics, nil
+	}
+
+	page, err := fetch(ctx, t)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// expired weeks are dropped as new ones come in, so the map stays around notificationWeeks in size
+	for k, w := range r.weeks {
+		if !now.Before(w.expires) {
+			delete(r.weeks, k)
+		}
+	}
+	r.weeks[key] = releasedWeek{comics: page.Results, expires: now.Add(r.ttl)}
+
+	return page.Results, nil
+}
+
+type notificationsView struct {
+	Since string
+	Weeks []weekView
+}
+
+func (v notificationsView) Count() int {
+	count := 0
+	for _, week := range v.Weeks {
+		count += len(week.Comics)
+	}
+
+	return count
+}
+
+func (s *Server) registerNotificationRoutes(router chi.Router) {
+	router.Get("/", s.handleNotifications)
+	router.Get("/count", s.handleNotificationCount)
+	router.Post("/seen", s.handleNotificationsSeen)
+}
+
+func (s *Server) handleNotifications(w http.ResponseWriter, r *http.Request) {
+	var view notificationsView
+	var v viewer
+
+	g, ctx := errgroup.WithContext(r.Context())
+	g.Go(func() error {
+		resp, err := s.notifications(ctx)
+		if err != nil {
+			return err
+		}
+
+		view = resp
+		return nil
+	})
+	g.Go(func() error {
+		resp, err := s.viewer(ctx)
+		if err != nil {
+			return err
+		}
+
+		v = resp
+		return nil
+	})
+
+	err := g.Wait()
+	if err != nil {
+		writeError(w, r, "could not get notifications", err)
+		return
+	}
+
+	content := View[notificationsView]{
+		Title:  "Notifications",
+		Resp:   view,
+		Viewer: v,
+	}
+
+	err = s.render(w, r, s.notificationsTmpl, "index.html", content)
+	if err != nil {
+		slog.ErrorContext(r.Context(), err.Error())
+	}
+}
+
+// handleNotificationCount is the badge in the navigation, loaded after the page so it does not hold the page up.
+func (s *Server) handleNotificationCount(w http.ResponseWriter, r *http.Request) {
+	view, err := s.notifications(r.Context())
+	if err != nil {
+		writeError(w, r, "could not get notifications", err)
+		return
+	}
+
+	err = s.render(w, r, s.notificationsTmpl, "notification-count", view.Count())
+	if err != nil {
+		slog.WarnContext(r.Context(), "error writing notification count", slog.String("err", err.Error()))
+	}
+}
+
+func (s *Server) handleNotificationsSeen(w http.ResponseWriter, r *http.Request) {
+	err := s.user.SeeNotifications(r.Context(), 0, today(r.Context())) // using default user id until auth actually implemented
+	if err != nil {
+		writeError(w, r, "could not mark notifications seen", err)
+		return
+	}
+
+	http.Redirect(w, r, "/notifications", http.StatusSeeOther)
+}
+
+// notifications are the issues released for the user's follows since they last marked them seen, newest week first.
+func (s *Server) notifications(ctx context.Context) (notificationsView, error) {
+	var follows comicshelf.Set[comicshelf.Follow]
+	var seen time.Time
+
+	g, gctx := errgroup.WithContext(ctx)
+	g.Go(func() error {
+		resp, err := s.user.Followed(gctx, 0) // using default user id until auth actually implemented
+		if err != nil {
+			return err
+		}
+
+		follows = resp
+		return nil
+	})
+	g.Go(func() error {
+		resp, err := s.user.NotificationsSeen(gctx, 0)
+		if err != nil {
+			return err
+		}
+
+		seen = resp
+		return nil
+	})
+
+	err := g.Wait()
+	if err != nil {
+		return notificationsView{}, err
+	}
+
+	now := today(ctx)
+	since := now.AddDate(0, 0, -7*notificationWeeks)
+	if seen.After(since) {
+		since = seen
+	}
+
+	view := notificationsView{Since: since.Format(justTheDateFormat)}
+	if len(follows) == 0 {
+		return view, nil
+	}
+
+	var weeks []time.Time
+	for t := now; !t.Before(since.AddDate(0, 0, -7)); t = t.AddDate(0, 0, -7) {
+		weeks = append(weeks, t)
+	}
+
+	view.Weeks = make([]weekView, len(weeks))
+	g, gctx = errgroup.WithContext(ctx)
+	for i, t := range weeks {
+		i, t := i, t
+		g.Go(func() error {
+			comics, err := s.released.get(gctx, t, s.comics.GetReleasedComics)
+			if err != nil {
+				return err
+			}
+
+			week := weekView{Date: t.Format(justTheDateFormat)}
+			for _, comic := range comics {
+				// since and now are noon on their day, release dates midnight
+				if comic.OnSaleDate.After(since) && comic.OnSaleDate.Before(now) && comicshelf.Pulled(follows, comic) {
+					week.Comics = append(week.Comics, comic)
+				}
+			}
+
+			view.Weeks[i] = week
+			return nil
+		})
+	}
+
+	err = g.Wait()
+	if err != nil {
+		return notificationsView{}, err
+	}
+
+	// weeks with nothing new are left out rather than shown empty
+	weeksWithComics := view.Weeks[:0]
+	for _, week := range view.Weeks {
+		if len(week.Comics) > 0 {
+			weeksWithComics = append(weeksWithComics, week)
+		}
+	}
+	view.Weeks = weeksWithComics
+
+	return view, nil
+}
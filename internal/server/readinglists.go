@@ -0,0 +1,447 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/jakedegiovanni/comicshelf"
+	"golang.org/x/sync/errgroup"
+)
+
+// maxListImport bounds the size of an uploaded reading list.
+const maxListImport = 1 << 20
+
+// maxListFetches bounds how many comics of a reading list are fetched at once, a long list would otherwise
+// start a request per comic all together.
+const maxListFetches = 8
+
+type readingListItem struct {
+	Comic comicshelf.Comic
+	Read  bool
+}
+
+type readingListView struct {
+	List  comicshelf.ReadingList
+	Owner bool
+	Items []readingListItem
+	Read  int
+}
+
+// readingListExport is the shareable json form of a reading list, titles are included for readability but only ids
+// are used on import.
+type readingListExport struct {
+	Name        string                   `json:"name"`
+	Description string                   `json:"description"`
+	Comics      []readingListExportComic `json:"comics"`
+}
+
+type readingListExportComic struct {
+	Id    int    `json:"id"`
+	Title string `json:"title"`
+}
+
+func (s *Server) registerReadingListRoutes(router chi.Router) {
+	router.Get("/", s.handleReadingLists)
+	router.Post("/", s.createReadingList)
+	router.Post("/import", s.importReadingList)
+	router.Get("/{listId}", s.handleReadingList)
+	router.Post("/{listId}", s.updateReadingList)
+	router.Delete("/{listId}", s.deleteReadingList)
+	router.Get("/{listId}/export", s.exportReadingList)
+	router.Post("/{listId}/order", s.reorderReadingList)
+	router.Post("/{listId}/comics", s.addToReadingList)
+	router.Delete("/{listId}/comics/{comicId}", s.removeFromReadingList)
+}
+
+func (s *Server) handleReadingLists(w http.ResponseWriter, r *http.Request) {
+	lists, err := s.lists.ReadingLists(r.Context(), 0) // using default user id until auth actually implemented
+	if err != nil {
+		writeError(w, r, "could not get reading lists", err)
+		return
+	}
+
+	content := View[[]comicshelf.ReadingList]{
+		Date:  r.URL.Query().Get("date"),
+		Title: "Reading Lists",
+		Resp:  lists,
+	}
+
+	err = s.render(w, r, s.readingListsTmpl, "index.html", content)
+	if err != nil {
+		slog.ErrorContext(r.Context(), err.Error())
+	}
+}
+
+func (s *Server) createReadingList(w http.ResponseWriter, r *http.Request) {
+	err := r.ParseForm()
+	if err != nil {
+		http.Error(w, "could not read form", http.StatusBadRequest)
+		return
+	}
+
+	name := r.PostFormValue("name")
+	if name == "" {
+		http.Error(w, "name key not present", http.StatusBadRequest)
+		return
+	}
+
+	list, err := s.lists.CreateReadingList(r.Context(), 0, comicshelf.ReadingList{ // using default user id until auth actually implemented
+		Name:        name,
+		Description: r.PostFormValue("description"),
+	})
+	if err != nil {
+		writeError(w, r, "could not create reading list", err)
+		return
+	}
+
+	http.Redirect(w, r, fmt.Sprintf("/lists/%d", list.Id), http.StatusSeeOther)
+}
+
+func (s *Server) importReadingList(w http.ResponseWriter, r *http.Request) {
+	f, _, err := r.FormFile("file")
+	if err != nil {
+		http.Error(w, "could not read uploaded file", http.StatusBadRequest)
+		return
+	}
+	defer f.Close()
+
+	var export readingListExport
+	err = json.NewDecoder(io.LimitReader(f, maxListImport)).Decode(&export)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("could not decode reading list: %s", err.Error()), http.StatusBadRequest)
+		return
+	}
+
+	if export.Name == "" {
+		http.Error(w, "reading list has no name", http.StatusBadRequest)
+		return
+	}
+
+	list := comicshelf.ReadingList{
+		Name:        export.Name,
+		Description: export.Description,
+		Comics:      make([]int, 0, len(export.Comics)),
+	}
+	for _, c := range export.Comics {
+		if !slices.Contains(list.Comics, c.Id) {
+			list.Comics = append(list.Comics, c.Id)
+		}
+	}
+
+	list, err = s.lists.CreateReadingList(r.Context(), 0, list) // using default user id until auth actually implemented
+	if err != nil {
+		writeError(w, r, "could not import reading list", err)
+		return
+	}
+
+	http.Redirect(w, r, fmt.Sprintf("/lists/%d", list.Id), http.StatusSeeOther)
+}
+
+func (s *Server) handleReadingList(w http.ResponseWriter, r *http.Request) {
+	view, ok := s.readingListView(w, r)
+	if !ok {
+		return
+	}
+
+	content := View[readingListView]{
+		Date:  r.URL.Query().Get("date"),
+		Title: view.List.Name,
+		Resp:  view,
+	}
+
+	err := s.render(w, r, s.readingListTmpl, "index.html", content)
+	if err != nil {
+		slog.ErrorContext(r.Context(), err.Error())
+	}
+}
+
+func (s *Server) updateReadingList(w http.ResponseWriter, r *http.Request) {
+	list, ok := s.ownedReadingList(w, r)
+	if !ok {
+		return
+	}
+
+	err := r.ParseForm()
+	if err != nil {
+		http.Error(w, "could not read form", http.StatusBadRequest)
+		return
+	}
+
+	if name := r.PostFormValue("name"); name != "" {
+		list.Name = name
+	}
+	list.Description = r.PostFormValue("description")
+	list.Shared = r.PostFormValue("shared") == "on"
+
+	_, err = s.lists.UpdateReadingList(r.Context(), 0, list) // using default user id until auth actually implemented
+	if err != nil {
+		writeError(w, r, fmt.Sprintf("could not update reading list: %d", list.Id), err)
+		return
+	}
+
+	http.Redirect(w, r, fmt.Sprintf("/lists/%d", list.Id), http.StatusSeeOther)
+}
+
+func (s *Server) deleteReadingList(w http.ResponseWriter, r *http.Request) {
+	list, ok := s.ownedReadingList(w, r)
+	if !ok {
+		return
+	}
+
+	err := s.lists.DeleteReadingList(r.Context(), 0, list.Id) // using default user id until auth actually implemented
+	if err != nil {
+		writeError(w, r, fmt.Sprintf("could not delete reading list: %d", list.Id), err)
+		return
+	}
+
+	w.Header().Set("HX-Redirect", "/lists")
+}
+
+func (s *Server) exportReadingList(w http.ResponseWriter, r *http.Request) {
+	view, ok := s.readingListView(w, r)
+	if !ok {
+		return
+	}
+
+	export := readingListExport{
+		Name:        view.List.Name,
+		Description: view.List.Description,
+		Comics:      make([]readingListExportComic, 0, len(view.Items)),
+	}
+	for _, item := range view.Items {
+		export.Comics = append(export.Comics, readingListExportComic{Id: item.Comic.Id, Title: item.Comic.Title})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="reading-list-%d.json"`, view.List.Id))
+
+	err := json.NewEncoder(w).Encode(export)
+	if err != nil {
+		slog.ErrorContext(r.Context(), err.Error())
+	}
+}
+
+func (s *Server) reorderReadingList(w http.ResponseWriter, r *http.Request) {
+	listId, ok := urlListId(w, r)
+	if !ok {
+		return
+	}
+
+	err := r.ParseForm()
+	if err != nil {
+		http.Error(w, "could not read form", http.StatusBadRequest)
+		return
+	}
+
+	order := make([]int, 0, len(r.PostForm["comic"]))
+	for _, c := range r.PostForm["comic"] {
+		id, err := strconv.Atoi(c)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("comic id is not a valid number: %s", c), http.StatusBadRequest)
+			return
+		}
+		order = append(order, id)
+	}
+
+	list, err := s.lists.ReorderReadingList(r.Context(), 0, listId, order) // using default user id until auth actually implemented
+	if errors.Is(err, comicshelf.ErrConflict) {
+		writeError(w, r, "reading list has changed, reload the page", err)
+		return
+	}
+	if err != nil {
+		writeError(w, r, fmt.Sprintf("could not reorder reading list: %d", listId), err)
+		return
+	}
+
+	s.renderReadingListItems(w, r, list)
+}
+
+func (s *Server) addToReadingList(w http.ResponseWriter, r *http.Request) {
+	listId, ok := urlListId(w, r)
+	if !ok {
+		return
+	}
+
+	err := r.ParseForm()
+	if err != nil {
+		http.Error(w, "could not read form", http.StatusBadRequest)
+		return
+	}
+
+	comicId, err := strconv.Atoi(r.PostFormValue("comic"))
+	if err != nil {
+		http.Error(w, fmt.Sprintf("comic id is not a valid number: %s", r.PostFormValue("comic")), http.StatusBadRequest)
+		return
+	}
+
+	_, err = s.comics.GetComic(r.Context(), comicId)
+	if err != nil {
+		writeError(w, r, fmt.Sprintf("could not get comic: %d", comicId), err)
+		return
+	}
+
+	list, err := s.lists.AddToReadingList(r.Context(), 0, listId, comicId) // using default user id until auth actually implemented
+	if err != nil {
+		writeError(w, r, fmt.Sprintf("could not update reading list: %d", listId), err)
+		return
+	}
+
+	// the comic page adds without showing the list, so only the editor gets the list items back
+	if r.Header.Get("HX-Target") != "list-items" {
+		err = s.render(w, r, s.readingListTmpl, "reading-list-added", list)
+		if err != nil {
+			slog.WarnContext(r.Context(), "error writing reading list added", slog.String("err", err.Error()))
+		}
+		return
+	}
+
+	s.renderReadingListItems(w, r, list)
+}
+
+func (s *Server) removeFromReadingList(w http.ResponseWriter, r *http.Request) {
+	listId, ok := urlListId(w, r)
+	if !ok {
+		return
+	}
+
+	comicId, err := strconv.Atoi(chi.URLParam(r, "comicId"))
+	if err != nil {
+		http.Error(w, "comic query is not a number", http.StatusUnprocessableEntity)
+		return
+	}
+
+	list, err := s.lists.RemoveFromReadingList(r.Context(), 0, listId, comicId) // using default user id until auth actually implemented
+	if err != nil {
+		writeError(w, r, fmt.Sprintf("could not update reading list: %d", listId), err)
+		return
+	}
+
+	s.renderReadingListItems(w, r, list)
+}
+
+func (s *Server) renderReadingListItems(w http.ResponseWriter, r *http.Request, list comicshelf.ReadingList) {
+	view, err := s.buildReadingListView(r, list)
+	if err != nil {
+		writeError(w, r, fmt.Sprintf("could not get reading list: %d", list.Id), err)
+		return
+	}
+
+	err = s.render(w, r, s.readingListTmpl, "reading-list-items", view)
+	if err != nil {
+		slog.WarnContext(r.Context(), "error writing reading list items", slog.String("err", err.Error()))
+	}
+}
+
+func urlListId(w http.ResponseWriter, r *http.Request) (int, bool) {
+	listId, err := strconv.Atoi(chi.URLParam(r, "listId"))
+	if err != nil {
+		http.Error(w, "list query is not a number", http.StatusUnprocessableEntity)
+		return 0, false
+	}
+
+	return listId, true
+}
+
+func (s *Server) readingList(w http.ResponseWriter, r *http.Request) (comicshelf.ReadingList, bool) {
+	listId, ok := urlListId(w, r)
+	if !ok {
+		return comicshelf.ReadingList{}, false
+	}
+
+	list, err := s.lists.ReadingList(r.Context(), 0, listId) // using default user id until auth actually implemented
+	if err != nil {
+		writeError(w, r, fmt.Sprintf("could not get reading list: %d", listId), err)
+		return comicshelf.ReadingList{}, false
+	}
+
+	return list, true
+}
+
+func (s *Server) ownedReadingList(w http.ResponseWriter, r *http.Request) (comicshelf.ReadingList, bool) {
+	list, ok := s.readingList(w, r)
+	if !ok {
+		return comicshelf.ReadingList{}, false
+	}
+
+	if list.OwnerId != 0 { // using default user id until auth actually implemented
+		writeError(w, r, fmt.Sprintf("could not get reading list: %d", list.Id), comicshelf.ErrNotFound)
+		return comicshelf.ReadingList{}, false
+	}
+
+	return list, true
+}
+
+func (s *Server) readingListView(w http.ResponseWriter, r *http.Request) (readingListView, bool) {
+	list, ok := s.readingList(w, r)
+	if !ok {
+		return readingListView{}, false
+	}
+
+	view, err := s.buildReadingListView(r, list)
+	if err != nil {
+		writeError(w, r, fmt.Sprintf("could not get reading list: %d", list.Id), err)
+		return readingListView{}, false
+	}
+
+	return view, true
+}
+
+func (s *Server) buildReadingListView(r *http.Request, list comicshelf.ReadingList) (readingListView, error) {
+	view := readingListView{
+		List:  list,
+		Owner: list.OwnerId == 0, // using default user id until auth actually implemented
+		Items: make([]readingListItem, len(list.Comics)),
+	}
+
+	var issues map[int]comicshelf.IssueStates
+
+	g, ctx := errgroup.WithContext(r.Context())
+	g.SetLimit(maxListFetches)
+	g.Go(func() error {
+		resp, err := s.user.IssueStates(ctx, 0) // using default user id until auth actually implemented
+		if err != nil {
+			return err
+		}
+
+		issues = resp
+		return nil
+	})
+
+	for i, id := range list.Comics {
+		i, id := i, id // https://golang.org/doc/faq#closures_and_goroutines
+		g.Go(func() error {
+			comic, err := s.comics.GetComic(ctx, id)
+			if err != nil && !errors.Is(err, comicshelf.ErrNotFound) {
+				return err
+			}
+
+			if err != nil {
+				comic = comicshelf.Comic{Id: id, Title: fmt.Sprintf("Unknown comic %d", id)}
+			}
+
+			view.Items[i].Comic = comic
+			return nil
+		})
+	}
+
+	err := g.Wait()
+	if err != nil {
+		return readingListView{}, err
+	}
+
+	for i := range view.Items {
+		if issues[view.Items[i].Comic.Id].Has(comicshelf.StateRead) {
+			view.Items[i].Read = true
+			view.Read++
+		}
+	}
+
+	return view, nil
+}
@@ -0,0 +1,62 @@
+package server
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/jakedegiovanni/comicshelf"
+)
+
+// maxQueryLen bounds a search query in characters, anything longer is not a title anyone is looking for.
+const maxQueryLen = 100
+
+type searchView struct {
+	Query   string
+	Results []comicshelf.SearchResult
+}
+
+func (s *Server) registerSearchRoutes(router chi.Router) {
+	router.Get("/", s.handleSearch)
+}
+
+func (s *Server) handleSearch(w http.ResponseWriter, r *http.Request) {
+	query := strings.TrimSpace(r.URL.Query().Get("q"))
+	if utf8.RuneCountInString(query) > maxQueryLen {
+		writeError(w, r, "could not search", fmt.Errorf("search query is longer than %d characters: %w", maxQueryLen, comicshelf.ErrInvalidInput))
+		return
+	}
+
+	view := searchView{Query: query}
+	if query != "" {
+		results, err := s.search.Search(r.Context(), query)
+		if err != nil {
+			writeError(w, r, "could not search", err)
+			return
+		}
+		view.Results = results
+	}
+
+	// htmx live search only wants the results swapped in, not the whole page
+	if r.Header.Get("HX-Request") == "true" {
+		err := s.render(w, r, s.searchTmpl, "search-results", view)
+		if err != nil {
+			slog.ErrorContext(r.Context(), err.Error())
+		}
+		return
+	}
+
+	content := View[searchView]{
+		Date:  r.URL.Query().Get("date"),
+		Title: "Search",
+		Resp:  view,
+	}
+
+	err := s.render(w, r, s.searchTmpl, "index.html", content)
+	if err != nil {
+		slog.ErrorContext(r.Context(), err.Error())
+	}
+}
@@ -1,20 +1,35 @@
 package server
 
 import (
+	"fmt"
 	"log/slog"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/jakedegiovanni/comicshelf"
+	"golang.org/x/sync/errgroup"
 )
 
+type seriesView struct {
+	Series    comicshelf.Series
+	Following bool
+	Read      int
+	Released  []comicshelf.Comic
+	Upcoming  []comicshelf.Comic
+}
+
+func (v seriesView) IssueCount() int {
+	return len(v.Released) + len(v.Upcoming)
+}
+
 func (s *Server) registerSeriesRoutes(router chi.Router) {
 	router.Get("/{seriesId}", s.handleSeries)
 }
 
 func (s *Server) handleSeries(w http.ResponseWriter, r *http.Request) {
-	slog.Debug(r.URL.String())
+	slog.DebugContext(r.Context(), r.URL.String())
 
 	seriesId := chi.URLParam(r, "seriesId")
 	id, err := strconv.Atoi(seriesId)
@@ -23,21 +38,54 @@ func (s *Server) handleSeries(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	resp, err := s.series.GetComicsWithinSeries(r.Context(), id)
+	var view seriesView
+
+	g, ctx := errgroup.WithContext(r.Context())
+	g.Go(func() error {
+		series, err := s.series.GetSeries(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		view.Series = series
+		return nil
+	})
+	var v viewer
+	g.Go(func() error {
+		resp, err := s.viewer(ctx)
+		if err != nil {
+			return err
+		}
+
+		v = resp
+		return nil
+	})
+
+	err = g.Wait()
 	if err != nil {
-		slog.Error(err.Error())
+		writeError(w, r, fmt.Sprintf("could not get series: %d", id), err)
 		return
 	}
 
-	content := View[[]comicshelf.Comic]{
-		Date:  r.URL.Query().Get("date"),
-		Title: "Series Issues",
-		Resp:  resp,
+	view.Following = v.Following(comicshelf.FollowSeries, id)
+	for _, comic := range view.Series.Comics {
+		if v.States[comic.Id].Has(comicshelf.StateRead) {
+			view.Read++
+		}
+	}
+
+	view.Released, view.Upcoming = splitReleased(view.Series.Comics, time.Now())
+
+	content := View[seriesView]{
+		Date:   r.URL.Query().Get("date"),
+		Title:  view.Series.Title,
+		Resp:   view,
+		Viewer: v,
 	}
 
-	err = s.seriesTmpl.ExecuteTemplate(w, "index.html", content)
+	err = s.render(w, r, s.seriesTmpl, "index.html", content)
 	if err != nil {
-		slog.Error(err.Error())
+		slog.ErrorContext(r.Context(), err.Error())
 		return
 	}
 }
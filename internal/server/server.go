@@ -2,6 +2,7 @@ package server
 
 import (
 	"context"
+	"crypto/tls"
 	"embed"
 	"errors"
 	"fmt"
@@ -10,105 +11,232 @@ import (
 	"log/slog"
 	"net/http"
 	"os"
-	"os/signal"
+	"path/filepath"
 	"runtime/debug"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/jakedegiovanni/comicshelf"
+	"github.com/jakedegiovanni/comicshelf/internal/metrics"
+	"github.com/jakedegiovanni/comicshelf/internal/tracing"
+	"golang.org/x/net/http2"
 	"golang.org/x/sync/errgroup"
 )
 
-const justTheDateFormat = "2006-01-02"
-
 //go:embed static
 var static embed.FS
 
 //go:embed templates
 var templates embed.FS
 
+// defaultDrainTimeout applies when no drain timeout is configured.
+const defaultDrainTimeout = 10 * time.Second
+
 type View[T any] struct {
-	Date  string
-	Title string
-	Resp  T
+	Date   string
+	Title  string
+	Resp   T
+	Viewer viewer
+	// CSRFToken is filled in by render, handlers leave it empty
+	CSRFToken string
+}
+
+// requestView is page data holding values that differ for every request, which render fills in
+// so the parsed templates can be shared rather than given per request funcs.
+type requestView interface {
+	forRequest(r *http.Request) any
+}
+
+func (v View[T]) forRequest(r *http.Request) any {
+	v.CSRFToken = csrfToken(r.Context())
+	return v
+}
+
+// page is a set of templates handlers render from, dev mode swaps in a fresh parse while requests are in flight.
+type page struct {
+	name     string
+	patterns []string
+
+	mu   sync.RWMutex
+	tmpl *template.Template
+}
+
+// newPage is a page made of the shared layout templates and those matching pattern.
+func newPage(name, pattern string) *page {
+	return &page{name: name, patterns: []string{"*.html", pattern}}
+}
+
+func (p *page) load(fsys fs.FS, funcs template.FuncMap) error {
+	tmpl, err := template.New(p.name).Funcs(funcs).ParseFS(fsys, p.patterns...)
+	if err != nil {
+		return fmt.Errorf("could not parse %s: %w", p.name, err)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.tmpl = tmpl
+	return nil
+}
+
+func (p *page) template() *template.Template {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.tmpl
 }
 
 type Server struct {
-	cfg        *Config
-	srv        *http.Server
-	comicTmpl  *template.Template
-	seriesTmpl *template.Template
-	comics     comicshelf.ComicService
-	series     comicshelf.SeriesService
-	user       comicshelf.UserService
+	cfg               *Config
+	srv               *http.Server
+	certs             *certReloader
+	assets            *assets
+	templateFiles     fs.FS
+	tmplFuncs         template.FuncMap
+	comicTmpl         *page
+	comicDetailTmpl   *page
+	seriesTmpl        *page
+	creatorTmpl       *page
+	characterTmpl     *page
+	searchTmpl        *page
+	readingListsTmpl  *page
+	readingListTmpl   *page
+	notificationsTmpl *page
+	released          *releasedWeeks
+	live              *liveReload
+	comics            comicshelf.ComicService
+	series            comicshelf.SeriesService
+	creators          comicshelf.CreatorService
+	characters        comicshelf.CharacterService
+	search            comicshelf.SearchService
+	user              comicshelf.UserService
+	lists             comicshelf.ReadingListService
+	checks            map[string]Check
 }
 
 func New(
 	config *Config,
 	comics comicshelf.ComicService,
 	series comicshelf.SeriesService,
+	creators comicshelf.CreatorService,
+	characters comicshelf.CharacterService,
+	search comicshelf.SearchService,
 	user comicshelf.UserService,
+	lists comicshelf.ReadingListService,
 ) (*Server, error) {
 	router := chi.NewRouter()
 
 	srv := &http.Server{
-		Handler: router,
-		Addr:    config.Address,
+		Handler:           router,
+		Addr:              config.Address,
+		ReadTimeout:       config.ReadTimeout,
+		ReadHeaderTimeout: config.ReadHeaderTimeout,
+		WriteTimeout:      config.WriteTimeout,
+		IdleTimeout:       config.IdleTimeout,
+		MaxHeaderBytes:    config.MaxHeaderBytes,
 	}
 
-	tmplFuncs := template.FuncMap{
-		"equals": strings.EqualFold,
-		"following": func(userId, seriesId int) bool {
-			// todo this shouldn't stay here when an actual db connection, don't want to be calling sequentially during template render
-			f, err := user.Following(context.TODO(), userId, seriesId)
-			if err != nil {
-				slog.Error(err.Error())
-				return false
-			}
-			slog.Debug(fmt.Sprintf("%+v", f))
-			return f
-		},
-		"justTheDate": func(t time.Time) string {
-			return t.Format(justTheDateFormat)
-		},
+	var certs *certReloader
+	if config.TLS.Enabled() {
+		var err error
+		certs, err = newCertReloader(&config.TLS)
+		if err != nil {
+			return nil, err
+		}
+
+		srv.TLSConfig = &tls.Config{
+			MinVersion:     tls.VersionTLS12,
+			GetCertificate: certs.getCertificate,
+		}
+	}
+
+	if config.HTTP2.Disabled {
+		// a non nil map stops net/http from negotiating h2 itself
+		srv.TLSNextProto = map[string]func(*http.Server, *tls.Conn, http.Handler){}
+	} else {
+		err := http2.ConfigureServer(srv, &http2.Server{
+			MaxConcurrentStreams: config.HTTP2.MaxConcurrentStreams,
+			MaxReadFrameSize:     config.HTTP2.MaxReadFrameSize,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("could not configure http2: %w", err)
+		}
 	}
 
-	templates, err := fs.Sub(templates, "templates")
+	templateFiles, staticFiles, err := sources(config)
 	if err != nil {
 		return nil, err
 	}
 
-	comicTmpl := template.Must(
-		template.
-			New("comicTmpl").
-			Funcs(tmplFuncs).
-			ParseFS(templates, "*.html", "comics/*.html"),
-	)
+	assets, err := newAssets(staticFiles, !config.Dev)
+	if err != nil {
+		return nil, err
+	}
 
-	seriesTmpl := template.Must(
-		template.
-			New("seriesTmpl").
-			Funcs(tmplFuncs).
-			ParseFS(templates, "*.html", "series/*.html"),
-	)
+	tmplFuncs := template.FuncMap{
+		"asset":    assets.url,
+		"hasAsset": assets.has,
+		"equals":   strings.EqualFold,
+		"card":     newCardView,
+		"percent": func(f float64) float64 {
+			return f * 100
+		},
+		"justTheDate": func(t time.Time) string {
+			// release dates are calendar days, converting them to the viewer's timezone would move them
+			return t.UTC().Format(justTheDateFormat)
+		},
+		"devMode": func() bool {
+			return config.Dev
+		},
+	}
 
 	s := &Server{
-		cfg:        config,
-		srv:        srv,
-		comicTmpl:  comicTmpl,
-		seriesTmpl: seriesTmpl,
-		comics:     comics,
-		series:     series,
-		user:       user,
+		cfg:               config,
+		srv:               srv,
+		certs:             certs,
+		assets:            assets,
+		templateFiles:     templateFiles,
+		tmplFuncs:         tmplFuncs,
+		comicTmpl:         newPage("comicTmpl", "comics/*.html"),
+		comicDetailTmpl:   newPage("comicDetailTmpl", "comic/*.html"),
+		seriesTmpl:        newPage("seriesTmpl", "series/*.html"),
+		creatorTmpl:       newPage("creatorTmpl", "creator/*.html"),
+		characterTmpl:     newPage("characterTmpl", "character/*.html"),
+		searchTmpl:        newPage("searchTmpl", "search/*.html"),
+		readingListsTmpl:  newPage("readingListsTmpl", "readinglists/*.html"),
+		readingListTmpl:   newPage("readingListTmpl", "readinglist/*.html"),
+		notificationsTmpl: newPage("notificationsTmpl", "notifications/*.html"),
+		released:          newReleasedWeeks(releasedWeeksTTL),
+		live:              newLiveReload(),
+		comics:            comics,
+		series:            series,
+		creators:          creators,
+		characters:        characters,
+		search:            search,
+		user:              user,
+		lists:             lists,
+		checks:            make(map[string]Check),
 	}
 
+	err = s.loadTemplates()
+	if err != nil {
+		return nil, err
+	}
+
+	router.Use(serverTracing())
+	router.Use(requestId())
 	router.Use(serverLogger())
+	router.Use(serverMetrics())
 	router.Use(middleware.Recoverer)
+	router.Use(compress(config.CompressionLevel))
+	router.Use(securityHeaders(&config.Security))
+	router.Use(cacheControl(config.CacheControl))
+	router.Use(viewerZone(config.Timezone))
+	router.Use(csrf())
 
 	router.Group(func(r chi.Router) {
-		r.Mount("/static/", http.FileServer(http.FS(static)))
+		r.Handle(staticPrefix+"*", assets)
 	})
 
 	router.Group(func(r chi.Router) {
@@ -122,42 +250,92 @@ func New(
 			s.registerSeriesRoutes(r)
 		})
 
+		r.Route("/creators", func(r chi.Router) {
+			s.registerCreatorRoutes(r)
+		})
+
+		r.Route("/characters", func(r chi.Router) {
+			s.registerCharacterRoutes(r)
+		})
+
+		r.Route("/search", func(r chi.Router) {
+			s.registerSearchRoutes(r)
+		})
+
+		r.Route("/lists", func(r chi.Router) {
+			s.registerReadingListRoutes(r)
+		})
+
+		r.Route("/notifications", func(r chi.Router) {
+			s.registerNotificationRoutes(r)
+		})
+
 		r.Route("/api", func(r chi.Router) {
 			s.registerUserRoutes(r)
 		})
 	})
 
-	router.Get("/health", func(w http.ResponseWriter, r *http.Request) {
-		w.Write([]byte("healthy"))
-	})
+	if config.Dev {
+		router.Get(liveReloadPath, s.handleLiveReload)
+		srv.RegisterOnShutdown(s.live.close)
+	}
+
+	router.Get("/livez", s.handleLive)
+	router.Get("/readyz", s.handleReady)
+	router.Get("/health", s.handleLive) // kept for anything still probing the old endpoint
+
+	router.Handle("/metrics", metrics.Handler())
 
 	return s, nil
 }
 
+// Run serves until ctx is cancelled, then drains in-flight requests for up to the configured drain timeout.
+// Connections still open after that are closed.
 func (s *Server) Run(ctx context.Context) error {
 	defer s.handlePanic()
 
-	g := new(errgroup.Group)
+	g, ctx := errgroup.WithContext(ctx)
 
 	g.Go(func() error {
-		c := make(chan os.Signal, 1)
-		signal.Notify(c, os.Interrupt)
-
-		select {
-		case <-ctx.Done():
-			slog.Info("programmatic shutdown")
-		case <-c:
-			slog.Info("signal shutdown")
+		<-ctx.Done()
+
+		timeout := s.cfg.DrainTimeout
+		if timeout <= 0 {
+			timeout = defaultDrainTimeout
 		}
+		slog.Info("draining server", slog.Duration("timeout", timeout))
 
-		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		drainCtx, cancel := context.WithTimeout(context.Background(), timeout)
 		defer cancel()
 
-		return s.srv.Shutdown(ctx)
+		err := s.srv.Shutdown(drainCtx)
+		if err != nil {
+			_ = s.srv.Close()
+			return fmt.Errorf("could not drain server: %w", err)
+		}
+
+		return nil
 	})
 
+	serve := s.srv.ListenAndServe
+	if s.certs != nil {
+		serve = func() error {
+			return s.srv.ListenAndServeTLS("", "") // certificates come from the reloader
+		}
+
+		g.Go(func() error {
+			return s.certs.watch(ctx)
+		})
+	}
+
+	if s.cfg.Dev {
+		g.Go(func() error {
+			return s.watchDev(ctx)
+		})
+	}
+
 	g.Go(func() error {
-		err := s.srv.ListenAndServe()
+		err := serve()
 		if err != nil {
 			if errors.Is(err, http.ErrServerClosed) {
 				return nil
@@ -170,10 +348,68 @@ func (s *Server) Run(ctx context.Context) error {
 		return nil
 	})
 
-	slog.Info("server ready to accept connections", slog.String("addr", s.cfg.Address))
+	slog.Info("server ready to accept connections", slog.String("addr", s.cfg.Address), slog.Bool("tls", s.certs != nil))
 	return g.Wait()
 }
 
+// render executes the named template for the request.
+func (s *Server) render(w http.ResponseWriter, r *http.Request, p *page, name string, data any) (err error) {
+	_, span := tracing.Start(r.Context(), "render "+name)
+	defer func() {
+		tracing.End(span, err)
+	}()
+
+	if v, ok := data.(requestView); ok {
+		data = v.forRequest(r)
+	}
+
+	// set up front as the compressor decides from the type before anything is written to sniff
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	return p.template().ExecuteTemplate(w, name, data)
+}
+
+func (s *Server) pages() []*page {
+	return []*page{
+		s.comicTmpl,
+		s.comicDetailTmpl,
+		s.seriesTmpl,
+		s.creatorTmpl,
+		s.characterTmpl,
+		s.searchTmpl,
+		s.readingListsTmpl,
+		s.readingListTmpl,
+		s.notificationsTmpl,
+	}
+}
+
+func (s *Server) loadTemplates() error {
+	var errs []error
+	for _, p := range s.pages() {
+		errs = append(errs, p.load(s.templateFiles, s.tmplFuncs))
+	}
+
+	return errors.Join(errs...)
+}
+
+// sources are the embedded templates and static files, or in dev mode the ones on disk so edits show without a rebuild.
+func sources(cfg *Config) (fs.FS, fs.FS, error) {
+	if cfg.Dev {
+		return os.DirFS(filepath.Join(cfg.DevDir, "templates")), os.DirFS(filepath.Join(cfg.DevDir, "static")), nil
+	}
+
+	templateFiles, err := fs.Sub(templates, "templates")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	staticFiles, err := fs.Sub(static, "static")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return templateFiles, staticFiles, nil
+}
+
 func (s *Server) handlePanic() {
 	if r := recover(); r != nil {
 		slog.Error("recovered", slog.Any("r", r))
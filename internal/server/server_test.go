@@ -0,0 +1,529 @@
+package server
+
+import (
+	"context"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jakedegiovanni/comicshelf"
+	"github.com/jakedegiovanni/comicshelf/internal/filedb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeCatalogue serves the same comics as every kind of catalogue the server reads from.
+type fakeCatalogue struct {
+	comics []comicshelf.Comic
+}
+
+func (f *fakeCatalogue) GetWeeklyComics(ctx context.Context, t time.Time) (comicshelf.Page[comicshelf.Comic], error) {
+	return comicshelf.Page[comicshelf.Comic]{Results: f.comics}, nil
+}
+
+// GetReleasedComics treats a week as the seven days up to t.
+func (f *fakeCatalogue) GetReleasedComics(ctx context.Context, t time.Time) (comicshelf.Page[comicshelf.Comic], error) {
+	var page comicshelf.Page[comicshelf.Comic]
+	for _, c := range f.comics {
+		if c.OnSaleDate.After(t.AddDate(0, 0, -7)) && !c.OnSaleDate.After(t) {
+			page.Results = append(page.Results, c)
+		}
+	}
+
+	return page, nil
+}
+
+func (f *fakeCatalogue) GetComic(ctx context.Context, id int) (comicshelf.Comic, error) {
+	for _, c := range f.comics {
+		if c.Id == id {
+			return c, nil
+		}
+	}
+
+	return comicshelf.Comic{}, comicshelf.ErrNotFound
+}
+
+func (f *fakeCatalogue) GetComicsWithinSeries(ctx context.Context, id int) ([]comicshelf.Comic, error) {
+	return f.comics, nil
+}
+
+func (f *fakeCatalogue) GetSeries(ctx context.Context, id int) (comicshelf.Series, error) {
+	return comicshelf.Series{Id: id, Comics: f.comics}, nil
+}
+
+func (f *fakeCatalogue) GetCreator(ctx context.Context, id int) (comicshelf.Creator, error) {
+	return comicshelf.Creator{Id: id}, nil
+}
+
+func (f *fakeCatalogue) GetComicsByCreator(ctx context.Context, id int, from, to time.Time) ([]comicshelf.Comic, error) {
+	return f.comics, nil
+}
+
+func (f *fakeCatalogue) GetCharacter(ctx context.Context, id int) (comicshelf.Character, error) {
+	return comicshelf.Character{Id: id}, nil
+}
+
+func (f *fakeCatalogue) GetComicsWithCharacter(ctx context.Context, id int, from, to time.Time) ([]comicshelf.Comic, error) {
+	return f.comics, nil
+}
+
+func (f *fakeCatalogue) Search(ctx context.Context, q string) ([]comicshelf.SearchResult, error) {
+	return nil, nil
+}
+
+func newTestServer(t *testing.T, cfg *Config, catalogue *fakeCatalogue) *Server {
+	t.Helper()
+
+	db, err := filedb.New(&filedb.Config{Filename: filepath.Join(t.TempDir(), "db.json")})
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		require.NoError(t, db.Shutdown(context.Background()))
+	})
+
+	s, err := New(cfg, catalogue, catalogue, catalogue, catalogue, catalogue, db, db)
+	require.NoError(t, err)
+	return s
+}
+
+func get(t *testing.T, s *Server, target string) *httptest.ResponseRecorder {
+	t.Helper()
+
+	rec := httptest.NewRecorder()
+	s.srv.Handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+	return rec
+}
+
+// post sends a form with a valid csrf token, as a page rendered by the server would.
+func post(t *testing.T, s *Server, target string, form url.Values) *httptest.ResponseRecorder {
+	t.Helper()
+
+	token := make([]byte, csrfTokenLen)
+	form.Set(csrfField, maskedToken(token))
+
+	req := httptest.NewRequest(http.MethodPost, target, strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.AddCookie(&http.Cookie{Name: csrfCookie, Value: base64.RawURLEncoding.EncodeToString(token)})
+
+	rec := httptest.NewRecorder()
+	s.srv.Handler.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestReleaseDatesIgnoreViewerZone(t *testing.T) {
+	onSale := time.Date(2023, time.August, 2, 0, 0, 0, 0, time.UTC)
+	s := newTestServer(t, &Config{}, &fakeCatalogue{comics: []comicshelf.Comic{
+		{Id: 1, SeriesId: 2, Title: "Issue", OnSaleDate: onSale, UnlimitedDate: onSale.AddDate(0, 6, 0)},
+	}})
+
+	for _, tz := range []string{"UTC", "America/Los_Angeles", "Pacific/Honolulu", "Asia/Tokyo"} {
+		t.Run(tz, func(t *testing.T) {
+			rec := get(t, s, "/comics/1?tz="+tz)
+			require.Equal(t, http.StatusOK, rec.Code)
+
+			body := rec.Body.String()
+			assert.Contains(t, body, "2023-08-02")
+			assert.Contains(t, body, "2024-02-02")
+			assert.NotContains(t, body, "2023-08-01")
+		})
+	}
+}
+
+func TestNotifications(t *testing.T) {
+	now := time.Now().UTC()
+	day := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	s := newTestServer(t, &Config{}, &fakeCatalogue{comics: []comicshelf.Comic{
+		{Id: 1, SeriesId: 10, Title: "Followed Series", OnSaleDate: day.AddDate(0, 0, -3)},
+		{Id: 2, SeriesId: 11, Title: "Followed Creator", OnSaleDate: day.AddDate(0, 0, -10),
+			Creators: []comicshelf.Credit{{Reference: comicshelf.Reference{Id: 20}}}},
+		{Id: 3, SeriesId: 12, Title: "Not Followed", OnSaleDate: day.AddDate(0, 0, -3)},
+		{Id: 4, SeriesId: 10, Title: "Too Old", OnSaleDate: day.AddDate(0, 0, -7*notificationWeeks-7)},
+		{Id: 5, SeriesId: 10, Title: "Upcoming", OnSaleDate: day.AddDate(0, 0, 3)},
+	}})
+
+	ctx := context.Background()
+	require.NoError(t, s.user.Follow(ctx, 0, comicshelf.Follow{Kind: comicshelf.FollowSeries, Id: 10}))
+	require.NoError(t, s.user.Follow(ctx, 0, comicshelf.Follow{Kind: comicshelf.FollowCreator, Id: 20}))
+
+	rec := get(t, s, "/notifications")
+	require.Equal(t, http.StatusOK, rec.Code)
+	body := rec.Body.String()
+	assert.Contains(t, body, "Followed Series")
+	assert.Contains(t, body, "Followed Creator")
+	assert.NotContains(t, body, "Not Followed")
+	assert.NotContains(t, body, "Too Old")
+	assert.NotContains(t, body, "Upcoming")
+
+	rec = get(t, s, "/notifications/count")
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), ">2<")
+
+	rec = post(t, s, "/notifications/seen", url.Values{})
+	require.Equal(t, http.StatusSeeOther, rec.Code)
+
+	rec = get(t, s, "/notifications/count")
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.NotContains(t, rec.Body.String(), "notification-count")
+}
+
+// countingReleases counts the weeks of releases fetched from the catalogue.
+type countingReleases struct {
+	*fakeCatalogue
+	released int
+}
+
+func (c *countingReleases) GetReleasedComics(ctx context.Context, t time.Time) (comicshelf.Page[comicshelf.Comic], error) {
+	c.released++
+	return c.fakeCatalogue.GetReleasedComics(ctx, t)
+}
+
+func TestNotificationCountReusesReleases(t *testing.T) {
+	db, err := filedb.New(&filedb.Config{Filename: filepath.Join(t.TempDir(), "db.json")})
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		require.NoError(t, db.Shutdown(context.Background()))
+	})
+
+	now := time.Now().UTC()
+	day := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	fake := &fakeCatalogue{comics: []comicshelf.Comic{
+		{Id: 1, SeriesId: 10, Title: "Followed Series", OnSaleDate: day.AddDate(0, 0, -3)},
+		{Id: 2, SeriesId: 11, Title: "Followed Later", OnSaleDate: day.AddDate(0, 0, -3)},
+	}}
+	catalogue := &countingReleases{fakeCatalogue: fake}
+
+	s, err := New(&Config{}, catalogue, fake, fake, fake, fake, db, db)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	require.NoError(t, db.Follow(ctx, 0, comicshelf.Follow{Kind: comicshelf.FollowSeries, Id: 10}))
+
+	rec := get(t, s, "/notifications/count")
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), ">1<")
+	fetched := catalogue.released
+	assert.Positive(t, fetched)
+
+	// a new follow shows up straight away, without fetching the weeks again
+	require.NoError(t, db.Follow(ctx, 0, comicshelf.Follow{Kind: comicshelf.FollowSeries, Id: 11}))
+	rec = get(t, s, "/notifications/count")
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), ">2<")
+	assert.Equal(t, fetched, catalogue.released)
+}
+
+// countingUsers counts the calls pages make for the viewer's issue states.
+type countingUsers struct {
+	comicshelf.UserService
+	issueStates int
+}
+
+func (c *countingUsers) IssueStates(ctx context.Context, userId int) (map[int]comicshelf.IssueStates, error) {
+	c.issueStates++
+	return c.UserService.IssueStates(ctx, userId)
+}
+
+func TestIssueStatesFetchedOncePerPage(t *testing.T) {
+	db, err := filedb.New(&filedb.Config{Filename: filepath.Join(t.TempDir(), "db.json")})
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		require.NoError(t, db.Shutdown(context.Background()))
+	})
+
+	onSale := time.Date(2023, time.August, 2, 0, 0, 0, 0, time.UTC)
+	catalogue := &fakeCatalogue{comics: []comicshelf.Comic{
+		{Id: 1, SeriesId: 2, Title: "First", OnSaleDate: onSale},
+		{Id: 2, SeriesId: 2, Title: "Second", OnSaleDate: onSale},
+		{Id: 3, SeriesId: 2, Title: "Third", OnSaleDate: onSale},
+	}}
+	users := &countingUsers{UserService: db}
+
+	s, err := New(&Config{}, catalogue, catalogue, catalogue, catalogue, catalogue, users, db)
+	require.NoError(t, err)
+
+	_, err = db.SetIssueState(context.Background(), 0, 2, comicshelf.StateRead, onSale)
+	require.NoError(t, err)
+
+	for _, target := range []string{"/comics?date=2023-08-02", "/series/2", "/creators/1", "/characters/1"} {
+		t.Run(target, func(t *testing.T) {
+			users.issueStates = 0
+
+			rec := get(t, s, target)
+			require.Equal(t, http.StatusOK, rec.Code)
+			assert.Equal(t, 1, users.issueStates)
+
+			// only the second comic's read toggle is set
+			assert.Equal(t, 1, strings.Count(rec.Body.String(), `class="tracked"`))
+		})
+	}
+}
+
+func TestWeeklyComicsFilter(t *testing.T) {
+	onSale := time.Date(2023, time.August, 2, 0, 0, 0, 0, time.UTC)
+	s := newTestServer(t, &Config{}, &fakeCatalogue{comics: []comicshelf.Comic{
+		{Id: 1, SeriesId: 1, Title: "Written By Someone", OnSaleDate: onSale, PageCount: 32,
+			Prices:   []comicshelf.Price{{Type: "printPrice", Price: 3.99}},
+			Creators: []comicshelf.Credit{{Reference: comicshelf.Reference{Id: 10, Name: "Jane Writer"}, Role: "writer"}}},
+		{Id: 2, SeriesId: 2, Title: "Starring Someone", OnSaleDate: onSale, PageCount: 48,
+			Prices:     []comicshelf.Price{{Type: "printPrice", Price: 5.99}},
+			Characters: []comicshelf.Reference{{Id: 20, Name: "Spider-Man (Peter Parker)"}}},
+		{Id: 3, SeriesId: 3, Title: "Nobody Credited", OnSaleDate: onSale},
+	}})
+
+	tests := []struct {
+		name   string
+		query  string
+		status int
+		shown  []string
+	}{
+		{
+			name:   "no filter",
+			status: http.StatusOK,
+			shown:  []string{"Written By Someone", "Starring Someone", "Nobody Credited"},
+		},
+		{
+			name:   "creator",
+			query:  "&creator=jane",
+			status: http.StatusOK,
+			shown:  []string{"Written By Someone"},
+		},
+		{
+			name:   "character",
+			query:  "&character=spider-man",
+			status: http.StatusOK,
+			shown:  []string{"Starring Someone"},
+		},
+		{
+			name:   "min pages",
+			query:  "&min_pages=40",
+			status: http.StatusOK,
+			shown:  []string{"Starring Someone"},
+		},
+		{
+			name:   "max price",
+			query:  "&max_price=4",
+			status: http.StatusOK,
+			shown:  []string{"Written By Someone"},
+		},
+		{
+			name:   "combined",
+			query:  "&creator=jane&min_pages=40",
+			status: http.StatusOK,
+		},
+		{
+			name:   "invalid min pages",
+			query:  "&min_pages=lots",
+			status: http.StatusBadRequest,
+		},
+		{
+			name:   "negative price",
+			query:  "&max_price=-1",
+			status: http.StatusBadRequest,
+		},
+	}
+
+	all := []string{"Written By Someone", "Starring Someone", "Nobody Credited"}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := get(t, s, "/comics?date=2023-08-02"+tt.query)
+			require.Equal(t, tt.status, rec.Code)
+			if tt.status != http.StatusOK {
+				return
+			}
+
+			body := rec.Body.String()
+			for _, title := range all {
+				if slices.Contains(tt.shown, title) {
+					assert.Contains(t, body, title)
+				} else {
+					assert.NotContains(t, body, title)
+				}
+			}
+		})
+	}
+
+	// moving to the next week keeps the filter
+	rec := get(t, s, "/comics?date=2023-08-02&creator=jane")
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), "creator=jane&amp;date=2023-08-09")
+}
+
+func TestWeeklyComicsRange(t *testing.T) {
+	s := newTestServer(t, &Config{}, &fakeCatalogue{})
+
+	tests := []struct {
+		name   string
+		query  string
+		status int
+		weeks  int
+	}{
+		{
+			name:   "single week",
+			query:  "from=2023-08-02&to=2023-08-08",
+			status: http.StatusOK,
+			weeks:  1,
+		},
+		{
+			name:   "largest range",
+			query:  "from=2023-08-02&to=" + time.Date(2023, time.August, 2+7*maxRangeWeeks-1, 0, 0, 0, 0, time.UTC).Format(justTheDateFormat),
+			status: http.StatusOK,
+			weeks:  maxRangeWeeks,
+		},
+		{
+			name:   "one week too many",
+			query:  "from=2023-08-02&to=" + time.Date(2023, time.August, 2+7*maxRangeWeeks, 0, 0, 0, 0, time.UTC).Format(justTheDateFormat),
+			status: http.StatusBadRequest,
+		},
+		{
+			name:   "every date there is",
+			query:  "from=0001-01-01&to=9999-12-31",
+			status: http.StatusBadRequest,
+		},
+		{
+			name:   "to before from",
+			query:  "from=2023-08-09&to=2023-08-02",
+			status: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := get(t, s, "/comics?"+tt.query)
+			require.Equal(t, tt.status, rec.Code)
+			if tt.status == http.StatusOK && tt.weeks > 1 {
+				// a single week is shown without a heading
+				assert.Equal(t, tt.weeks, strings.Count(rec.Body.String(), "Week of"))
+			}
+		})
+	}
+}
+
+func TestComicPage(t *testing.T) {
+	onSale := time.Date(2023, time.August, 2, 0, 0, 0, 0, time.UTC)
+	s := newTestServer(t, &Config{}, &fakeCatalogue{comics: []comicshelf.Comic{
+		{Id: 1, SeriesId: 2, IssuerNumber: 1, Title: "Issue One", OnSaleDate: onSale},
+		{Id: 2, SeriesId: 2, IssuerNumber: 2, Title: "Issue Two", OnSaleDate: onSale},
+		{Id: 3, SeriesId: 2, IssuerNumber: 3, Title: "Issue Three", OnSaleDate: onSale},
+	}})
+
+	ctx := context.Background()
+	require.NoError(t, s.user.Follow(ctx, 0, comicshelf.Follow{Kind: comicshelf.FollowSeries, Id: 2}))
+	_, err := s.user.SetIssueState(ctx, 0, 2, comicshelf.StateRead, onSale)
+	require.NoError(t, err)
+
+	tests := []struct {
+		target   string
+		previous string
+		next     string
+		tracked  int
+	}{
+		{target: "/comics/1", next: "Issue Two"},
+		{target: "/comics/2", previous: "Issue One", next: "Issue Three", tracked: 1},
+		{target: "/comics/3", previous: "Issue Two"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.target, func(t *testing.T) {
+			rec := get(t, s, tt.target)
+			require.Equal(t, http.StatusOK, rec.Code)
+			body := rec.Body.String()
+
+			assert.Contains(t, body, "/api/unfollow", "the followed series shows as followed")
+			assert.Equal(t, tt.tracked, strings.Count(body, `class="tracked"`))
+
+			if tt.previous != "" {
+				assert.Contains(t, body, "&larr; "+tt.previous)
+			} else {
+				assert.NotContains(t, body, "&larr; Issue")
+			}
+
+			if tt.next != "" {
+				assert.Contains(t, body, tt.next+" &rarr;")
+			} else {
+				assert.NotContains(t, body, "&rarr;</a>")
+			}
+		})
+	}
+}
+
+// windowCatalogue records the release window pages ask creators and characters for.
+type windowCatalogue struct {
+	*fakeCatalogue
+	from, to time.Time
+}
+
+func (c *windowCatalogue) GetComicsByCreator(ctx context.Context, id int, from, to time.Time) ([]comicshelf.Comic, error) {
+	c.from, c.to = from, to
+	return c.fakeCatalogue.GetComicsByCreator(ctx, id, from, to)
+}
+
+func (c *windowCatalogue) GetComicsWithCharacter(ctx context.Context, id int, from, to time.Time) ([]comicshelf.Comic, error) {
+	c.from, c.to = from, to
+	return c.fakeCatalogue.GetComicsWithCharacter(ctx, id, from, to)
+}
+
+func TestReleasesPages(t *testing.T) {
+	db, err := filedb.New(&filedb.Config{Filename: filepath.Join(t.TempDir(), "db.json")})
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		require.NoError(t, db.Shutdown(context.Background()))
+	})
+
+	loc, err := time.LoadLocation("Pacific/Kiritimati")
+	require.NoError(t, err)
+	day := calendarDay(time.Now().In(loc))
+
+	fake := &fakeCatalogue{comics: []comicshelf.Comic{
+		{Id: 1, SeriesId: 1, Title: "Out Already", OnSaleDate: day.AddDate(0, 0, -1)},
+		{Id: 2, SeriesId: 1, Title: "Out Soon", OnSaleDate: day.AddDate(0, 0, 1)},
+	}}
+	catalogue := &windowCatalogue{fakeCatalogue: fake}
+
+	s, err := New(&Config{}, fake, fake, catalogue, catalogue, fake, db, db)
+	require.NoError(t, err)
+
+	for _, target := range []string{"/creators/1", "/characters/1"} {
+		t.Run(target, func(t *testing.T) {
+			rec := get(t, s, target+"?tz=Pacific/Kiritimati")
+			require.Equal(t, http.StatusOK, rec.Code)
+
+			// the window is centred on the viewer's day, not the server's
+			assert.Equal(t, day.AddDate(0, -releaseWindowMonths, 0), catalogue.from)
+			assert.Equal(t, day.AddDate(0, releaseWindowMonths, 0), catalogue.to)
+
+			body := rec.Body.String()
+			require.Contains(t, body, "Out Already")
+			require.Contains(t, body, "Out Soon")
+			assert.Less(t, strings.Index(body, "Out Soon"), strings.Index(body, "Out Already"), "upcoming issues are listed first")
+
+			rec = get(t, s, target+"x")
+			assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+		})
+	}
+}
+
+func TestSearchQueryLength(t *testing.T) {
+	s := newTestServer(t, &Config{}, &fakeCatalogue{})
+
+	tests := []struct {
+		name   string
+		query  string
+		status int
+	}{
+		{name: "empty", status: http.StatusOK},
+		{name: "at the limit", query: strings.Repeat("é", maxQueryLen), status: http.StatusOK},
+		{name: "too long", query: strings.Repeat("a", maxQueryLen+1), status: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := get(t, s, "/search?q="+url.QueryEscape(tt.query))
+			assert.Equal(t, tt.status, rec.Code)
+		})
+	}
+}
@@ -0,0 +1,4 @@
+// only linked in dev mode, refreshes the page whenever the server reloads templates or static files
+new EventSource("/dev/reload").addEventListener("reload", function () {
+    window.location.reload();
+});
@@ -3,3 +3,53 @@ function activateNavItems() {
     if (e == null) return;
     e.classList.add("nav-item-active");
 }
+
+// dates are rendered in the zone from the tz cookie, default it to the browser's own zone
+function rememberTimezone() {
+    if (document.cookie.split("; ").some((c) => c.startsWith("tz="))) return;
+    const tz = Intl.DateTimeFormat().resolvedOptions().timeZone;
+    if (tz) document.cookie = "tz=" + tz + "; path=/; max-age=31536000; samesite=lax";
+}
+
+rememberTimezone();
+
+document.addEventListener("DOMContentLoaded", activateNavItems);
+
+// sortable lists reorder their items by drag and drop, then fire "end" so htmx can submit the new order
+function initSortable(root) {
+    root.querySelectorAll(".sortable").forEach(function (list) {
+        if (list.dataset.sortable) return;
+        list.dataset.sortable = "true";
+
+        let dragged = null;
+
+        list.addEventListener("dragstart", function (e) {
+            dragged = e.target.closest("[draggable]");
+            e.dataTransfer.effectAllowed = "move";
+        });
+
+        list.addEventListener("dragover", function (e) {
+            const target = e.target.closest("[draggable]");
+            if (dragged == null || target == null || target === dragged) return;
+            e.preventDefault();
+
+            const rect = target.getBoundingClientRect();
+            const after = e.clientY > rect.top + rect.height / 2;
+            target.parentNode.insertBefore(dragged, after ? target.nextSibling : target);
+        });
+
+        list.addEventListener("drop", function (e) {
+            e.preventDefault();
+        });
+
+        list.addEventListener("dragend", function () {
+            if (dragged == null) return;
+            dragged = null;
+            list.dispatchEvent(new Event("end"));
+        });
+    });
+}
+
+document.addEventListener("htmx:load", function (e) {
+    initSortable(e.target);
+});
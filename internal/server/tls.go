@@ -0,0 +1,100 @@
+package server
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"log/slog"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// reloadSettle is how long the tls files must go unchanged before they are reloaded.
+const reloadSettle = 500 * time.Millisecond
+
+// certReloader hands out the most recently loaded certificate so rotated certificates are picked up without a restart.
+type certReloader struct {
+	cfg *TLSConfig
+
+	mu   sync.RWMutex
+	cert *tls.Certificate
+}
+
+func newCertReloader(cfg *TLSConfig) (*certReloader, error) {
+	c := &certReloader{cfg: cfg}
+	err := c.load()
+	if err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+func (c *certReloader) load() error {
+	cert, err := tls.LoadX509KeyPair(c.cfg.CertFile, c.cfg.KeyFile)
+	if err != nil {
+		return fmt.Errorf("could not load tls key pair: %w", err)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.cert = &cert
+	return nil
+}
+
+func (c *certReloader) getCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.cert, nil
+}
+
+// watch reloads the key pair until ctx is done.
+// The directories are watched rather than the files as rotation usually swaps files in by rename, which drops a watch on the file itself.
+func (c *certReloader) watch(ctx context.Context) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("could not watch tls files: %w", err)
+	}
+	defer watcher.Close()
+
+	dirs := map[string]bool{
+		filepath.Dir(c.cfg.CertFile): true,
+		filepath.Dir(c.cfg.KeyFile):  true,
+	}
+	for dir := range dirs {
+		err = watcher.Add(dir)
+		if err != nil {
+			return fmt.Errorf("could not watch tls directory %s: %w", dir, err)
+		}
+	}
+
+	// rotating a pair is several events, wait for them to settle so the cert and key are read together
+	settle := time.NewTimer(0)
+	<-settle.C
+	defer settle.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case err := <-watcher.Errors:
+			slog.Warn("tls watch error", slog.String("err", err.Error()))
+		case event := <-watcher.Events:
+			if !event.Has(fsnotify.Chmod) {
+				settle.Reset(reloadSettle)
+			}
+		case <-settle.C:
+			// a mismatched pair fails to load, the old certificate is kept until the next change completes it
+			err := c.load()
+			if err != nil {
+				slog.Warn("could not reload tls certificate", slog.String("err", err.Error()))
+				continue
+			}
+
+			slog.Info("reloaded tls certificate")
+		}
+	}
+}
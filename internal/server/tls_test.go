@@ -0,0 +1,119 @@
+package server
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writePair writes a fresh self signed certificate and its key into dir under the given names.
+func writePair(t *testing.T, dir, certName, keyName, commonName string) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		DNSNames:     []string{"localhost"},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	certPem := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPem := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer})
+	require.NoError(t, os.WriteFile(filepath.Join(dir, certName), certPem, 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, keyName), keyPem, 0600))
+}
+
+func servedName(t *testing.T, c *certReloader) string {
+	t.Helper()
+
+	cert, err := c.getCertificate(nil)
+	require.NoError(t, err)
+
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	require.NoError(t, err)
+	return leaf.Subject.CommonName
+}
+
+func newTestCertReloader(t *testing.T) (*certReloader, string) {
+	t.Helper()
+
+	dir := t.TempDir()
+	writePair(t, dir, "tls.crt", "tls.key", "first")
+
+	c, err := newCertReloader(&TLSConfig{CertFile: filepath.Join(dir, "tls.crt"), KeyFile: filepath.Join(dir, "tls.key")})
+	require.NoError(t, err)
+	return c, dir
+}
+
+func TestCertReloaderLoad(t *testing.T) {
+	c, _ := newTestCertReloader(t)
+	assert.Equal(t, "first", servedName(t, c))
+
+	dir := t.TempDir()
+	_, err := newCertReloader(&TLSConfig{CertFile: filepath.Join(dir, "missing.crt"), KeyFile: filepath.Join(dir, "missing.key")})
+	assert.Error(t, err)
+}
+
+func TestCertReloaderKeepsPairOnFailedReload(t *testing.T) {
+	c, dir := newTestCertReloader(t)
+
+	// a certificate whose key has not been written yet
+	writePair(t, dir, "tls.crt", "other.key", "second")
+	assert.Error(t, c.load())
+	assert.Equal(t, "first", servedName(t, c))
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "tls.key"), []byte("not a key"), 0600))
+	assert.Error(t, c.load())
+	assert.Equal(t, "first", servedName(t, c))
+}
+
+func TestCertReloaderWatchesRotation(t *testing.T) {
+	c, dir := newTestCertReloader(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- c.watch(ctx)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		require.NoError(t, <-done)
+	})
+
+	// give the watcher time to start before rotating underneath it
+	time.Sleep(100 * time.Millisecond)
+
+	// rotated the way most tooling does it, written aside and renamed into place one file at a time
+	staging := t.TempDir()
+	writePair(t, staging, "tls.crt", "tls.key", "second")
+
+	require.NoError(t, os.Rename(filepath.Join(staging, "tls.crt"), filepath.Join(dir, "tls.crt")))
+	time.Sleep(2 * reloadSettle)
+	assert.Equal(t, "first", servedName(t, c), "half a pair keeps the old certificate")
+
+	require.NoError(t, os.Rename(filepath.Join(staging, "tls.key"), filepath.Join(dir, "tls.key")))
+	assert.Eventually(t, func() bool {
+		return servedName(t, c) == "second"
+	}, 5*time.Second, 50*time.Millisecond)
+}
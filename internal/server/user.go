@@ -1,74 +1,202 @@
 package server
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"log/slog"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/go-chi/chi/v5"
+	"github.com/jakedegiovanni/comicshelf"
+	"golang.org/x/sync/errgroup"
 )
 
 func (s *Server) registerUserRoutes(router chi.Router) {
 	router.Post("/follow", s.registerFollow)
 	router.Post("/unfollow", s.registerUnfollow)
+	router.Post("/track", s.registerIssueState)
+}
+
+// viewer is what pages show about the user looking at them, fetched once by the handler rather than per card.
+type viewer struct {
+	Follows comicshelf.Set[comicshelf.Follow]
+	States  map[int]comicshelf.IssueStates
+}
+
+func (v viewer) Following(kind comicshelf.FollowKind, id int) bool {
+	return v.Follows.Has(comicshelf.Follow{Kind: kind, Id: id})
+}
+
+func (s *Server) viewer(ctx context.Context) (viewer, error) {
+	var v viewer
+
+	g, ctx := errgroup.WithContext(ctx)
+	g.Go(func() error {
+		resp, err := s.user.Followed(ctx, 0) // using default user id until auth actually implemented
+		if err != nil {
+			return err
+		}
+
+		v.Follows = resp
+		return nil
+	})
+	g.Go(func() error {
+		resp, err := s.user.IssueStates(ctx, 0) // using default user id until auth actually implemented
+		if err != nil {
+			return err
+		}
+
+		v.States = resp
+		return nil
+	})
+
+	err := g.Wait()
+	if err != nil {
+		return viewer{}, err
+	}
+
+	return v, nil
+}
+
+// cardView is a comic as its card shows it to the viewer.
+type cardView struct {
+	comicshelf.Comic
+	FollowingSeries bool
+	Tracker         trackerView
+}
+
+func newCardView(v viewer, comic comicshelf.Comic) cardView {
+	return cardView{
+		Comic:           comic,
+		FollowingSeries: v.Following(comicshelf.FollowSeries, comic.SeriesId),
+		Tracker:         trackerView{ComicId: comic.Id, States: v.States[comic.Id]},
+	}
+}
+
+type trackerView struct {
+	ComicId int
+	States  comicshelf.IssueStates
+}
+
+type trackerToggle struct {
+	State comicshelf.IssueState
+	Label string
+	Set   bool
+}
+
+func (v trackerView) Toggles() []trackerToggle {
+	return []trackerToggle{
+		{State: comicshelf.StateRead, Label: "Read", Set: v.States.Has(comicshelf.StateRead)},
+		{State: comicshelf.StateOwnedDigital, Label: "Digital", Set: v.States.Has(comicshelf.StateOwnedDigital)},
+		{State: comicshelf.StateOwnedPhysical, Label: "Physical", Set: v.States.Has(comicshelf.StateOwnedPhysical)},
+		{State: comicshelf.StateWishlist, Label: "Wishlist", Set: v.States.Has(comicshelf.StateWishlist)},
+	}
 }
 
 func (s *Server) registerFollow(w http.ResponseWriter, r *http.Request) {
-	id, err := s.extractSeriesIdFromForm(r)
+	follow, err := s.extractFollowFromForm(r)
 	if err != nil {
-		http.Error(w, fmt.Sprintf("could not extract series id: %s", err.Error()), http.StatusBadRequest)
+		http.Error(w, fmt.Sprintf("could not extract follow: %s", err.Error()), http.StatusBadRequest)
 		return
 	}
-	slog.Debug(fmt.Sprintf("%d", id))
+	slog.DebugContext(r.Context(), fmt.Sprintf("%+v", follow))
 
-	err = s.user.Follow(r.Context(), 0, id) // using default user id until auth actually implemented
+	err = s.user.Follow(r.Context(), 0, follow) // using default user id until auth actually implemented
 	if err != nil {
-		http.Error(w, fmt.Sprintf("could not follow series with id: %d", id), http.StatusInternalServerError)
+		writeError(w, r, fmt.Sprintf("could not follow %s with id: %d", follow.Kind, follow.Id), err)
 		return
 	}
 
-	err = s.comicTmpl.ExecuteTemplate(w, "unfollow", nil)
+	err = s.render(w, r, s.comicTmpl, "unfollow", nil)
 	if err != nil {
-		slog.Warn("error writing unfollow", slog.String("err", err.Error()))
+		slog.WarnContext(r.Context(), "error writing unfollow", slog.String("err", err.Error()))
 	}
 }
 
 func (s *Server) registerUnfollow(w http.ResponseWriter, r *http.Request) {
-	id, err := s.extractSeriesIdFromForm(r)
+	follow, err := s.extractFollowFromForm(r)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("could not extract follow: %s", err.Error()), http.StatusBadRequest)
+		return
+	}
+
+	err = s.user.Unfollow(r.Context(), 0, follow) // using default user id until auth actually implemented
+	if err != nil {
+		writeError(w, r, fmt.Sprintf("could not unfollow %s with id: %d", follow.Kind, follow.Id), err)
+		return
+	}
+
+	err = s.render(w, r, s.comicTmpl, "follow", nil)
+	if err != nil {
+		slog.WarnContext(r.Context(), "error writing follow", slog.String("err", err.Error()))
+	}
+}
+
+func (s *Server) registerIssueState(w http.ResponseWriter, r *http.Request) {
+	err := r.ParseForm()
+	if err != nil {
+		http.Error(w, "could not read form", http.StatusBadRequest)
+		return
+	}
+
+	comicId, err := strconv.Atoi(r.PostFormValue("comic"))
 	if err != nil {
-		http.Error(w, fmt.Sprintf("could not extract series id: %s", err.Error()), http.StatusBadRequest)
+		http.Error(w, fmt.Sprintf("comic id is not a valid number: %s", r.PostFormValue("comic")), http.StatusBadRequest)
 		return
 	}
 
-	err = s.user.Unfollow(r.Context(), 0, id) // using default user id until auth actually implemented
+	state := comicshelf.IssueState(r.PostFormValue("state"))
+	if !state.Valid() {
+		http.Error(w, fmt.Sprintf("unknown issue state: %s", state), http.StatusBadRequest)
+		return
+	}
+
+	set, err := strconv.ParseBool(r.PostFormValue("set"))
 	if err != nil {
-		http.Error(w, fmt.Sprintf("could not unfollow series with id: %d", id), http.StatusInternalServerError)
+		http.Error(w, fmt.Sprintf("set is not a valid bool: %s", r.PostFormValue("set")), http.StatusBadRequest)
 		return
 	}
 
-	err = s.comicTmpl.ExecuteTemplate(w, "follow", nil)
+	var states comicshelf.IssueStates
+	if set {
+		states, err = s.user.SetIssueState(r.Context(), 0, comicId, state, time.Now()) // using default user id until auth actually implemented
+	} else {
+		states, err = s.user.ClearIssueState(r.Context(), 0, comicId, state) // using default user id until auth actually implemented
+	}
 	if err != nil {
-		slog.Warn("error writing follow", slog.String("err", err.Error()))
+		writeError(w, r, fmt.Sprintf("could not track comic with id: %d", comicId), err)
+		return
+	}
+
+	err = s.render(w, r, s.comicTmpl, "tracker", trackerView{ComicId: comicId, States: states})
+	if err != nil {
+		slog.WarnContext(r.Context(), "error writing tracker", slog.String("err", err.Error()))
 	}
 }
 
-func (s *Server) extractSeriesIdFromForm(r *http.Request) (int, error) {
+func (s *Server) extractFollowFromForm(r *http.Request) (comicshelf.Follow, error) {
 	err := r.ParseForm()
 	if err != nil {
-		return 0, errors.New("could not read form")
+		return comicshelf.Follow{}, errors.New("could not read form")
+	}
+
+	kind := comicshelf.FollowKind(r.PostFormValue("kind"))
+	if !kind.Valid() {
+		return comicshelf.Follow{}, fmt.Errorf("unknown follow kind: %s", kind)
 	}
 
-	seriesId := r.PostFormValue("series")
-	if seriesId == "" {
-		return 0, errors.New("series key not present")
+	followId := r.PostFormValue("id")
+	if followId == "" {
+		return comicshelf.Follow{}, errors.New("id key not present")
 	}
 
-	id, err := strconv.Atoi(seriesId)
+	id, err := strconv.Atoi(followId)
 	if err != nil {
-		return 0, fmt.Errorf("series id is not a valid number: %s", seriesId)
+		return comicshelf.Follow{}, fmt.Errorf("id is not a valid number: %s", followId)
 	}
 
-	return id, nil
+	return comicshelf.Follow{Kind: kind, Id: id}, nil
 }
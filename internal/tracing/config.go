@@ -0,0 +1,12 @@
+package tracing
+
+type Config struct {
+	// Exporter is where spans go: none, stdout or otlp.
+	Exporter string `mapstructure:"exporter"`
+	// Endpoint is the host:port of the OTLP/HTTP collector.
+	Endpoint string `mapstructure:"endpoint"`
+	// Insecure sends to the collector over plain http, for a local collector.
+	Insecure bool `mapstructure:"insecure"`
+	// SampleRatio is the fraction of new traces kept, traces started upstream follow the caller's decision.
+	SampleRatio float64 `mapstructure:"sample_ratio"`
+}
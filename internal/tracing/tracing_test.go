@@ -0,0 +1,111 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/jakedegiovanni/comicshelf"
+	"github.com/jakedegiovanni/comicshelf/internal/comicclient"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// record installs a global provider whose spans are kept for the test to inspect.
+func record(t *testing.T) *tracetest.SpanRecorder {
+	t.Helper()
+
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	return recorder
+}
+
+func attr(span sdktrace.ReadOnlySpan, key attribute.Key) attribute.Value {
+	for _, kv := range span.Attributes() {
+		if kv.Key == key {
+			return kv.Value
+		}
+	}
+
+	return attribute.Value{}
+}
+
+func TestClientMiddleware(t *testing.T) {
+	recorder := record(t)
+
+	var sent http.Header
+	transport := ClientMiddleware()(comicclient.MiddlewareFn(func(req *http.Request) (*http.Response, error) {
+		sent = req.Header
+		return &http.Response{StatusCode: http.StatusNotFound}, nil
+	}))
+
+	ctx, parent := Start(context.Background(), "parent")
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "https://example.com/comics/1", nil)
+	require.NoError(t, err)
+
+	_, err = transport.RoundTrip(req)
+	require.NoError(t, err)
+	parent.End()
+
+	assert.Empty(t, req.Header.Get("traceparent"), "the caller's request is left alone")
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 2)
+	client := spans[0]
+	assert.Equal(t, "HTTP GET", client.Name())
+	assert.Equal(t, trace.SpanKindClient, client.SpanKind())
+	assert.Equal(t, parent.SpanContext().SpanID(), client.Parent().SpanID())
+	assert.Equal(t, codes.Error, client.Status().Code)
+	assert.Equal(t, int64(http.StatusNotFound), attr(client, "http.response.status_code").AsInt64())
+
+	// the upstream sees the client span as its parent
+	sc := client.SpanContext()
+	assert.Equal(t, "00-"+sc.TraceID().String()+"-"+sc.SpanID().String()+"-01", sent.Get("traceparent"))
+}
+
+type fakeUsers struct {
+	comicshelf.UserService
+	err error
+}
+
+func (f fakeUsers) Followed(ctx context.Context, userId int) (comicshelf.Set[comicshelf.Follow], error) {
+	if !trace.SpanContextFromContext(ctx).IsValid() {
+		return nil, errors.New("called without the span in its context")
+	}
+
+	return comicshelf.Set[comicshelf.Follow]{}, f.err
+}
+
+func TestUserService(t *testing.T) {
+	recorder := record(t)
+
+	ctx, parent := Start(context.Background(), "parent")
+	_, err := NewUserService(fakeUsers{}).Followed(ctx, 3)
+	require.NoError(t, err)
+
+	_, err = NewUserService(fakeUsers{err: comicshelf.ErrNotFound}).Followed(ctx, 3)
+	require.ErrorIs(t, err, comicshelf.ErrNotFound)
+	parent.End()
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 3)
+	for _, span := range spans[:2] {
+		assert.Equal(t, "UserService.Followed", span.Name())
+		assert.Equal(t, parent.SpanContext().SpanID(), span.Parent().SpanID())
+		assert.Equal(t, int64(3), attr(span, "user.id").AsInt64())
+	}
+
+	assert.Equal(t, codes.Unset, spans[0].Status().Code)
+	assert.Equal(t, codes.Error, spans[1].Status().Code)
+	require.Len(t, spans[1].Events(), 1)
+	assert.Equal(t, "exception", spans[1].Events()[0].Name)
+}
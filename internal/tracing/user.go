@@ -0,0 +1,83 @@
+package tracing
+
+import (
+	"context"
+	"time"
+
+	"github.com/jakedegiovanni/comicshelf"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+var _ comicshelf.UserService = (*UserService)(nil)
+
+// UserService spans every call made to the wrapped service.
+type UserService struct {
+	next comicshelf.UserService
+}
+
+func NewUserService(next comicshelf.UserService) *UserService {
+	return &UserService{next: next}
+}
+
+func (u *UserService) Following(ctx context.Context, userId int, follow comicshelf.Follow) (_ bool, err error) {
+	ctx, span := Start(ctx, "UserService.Following", attribute.Int("user.id", userId), attribute.String("follow", follow.String()))
+	defer func() { End(span, err) }()
+
+	return u.next.Following(ctx, userId, follow)
+}
+
+func (u *UserService) Followed(ctx context.Context, userId int) (_ comicshelf.Set[comicshelf.Follow], err error) {
+	ctx, span := Start(ctx, "UserService.Followed", attribute.Int("user.id", userId))
+	defer func() { End(span, err) }()
+
+	return u.next.Followed(ctx, userId)
+}
+
+func (u *UserService) Follow(ctx context.Context, userId int, follow comicshelf.Follow) (err error) {
+	ctx, span := Start(ctx, "UserService.Follow", attribute.Int("user.id", userId), attribute.String("follow", follow.String()))
+	defer func() { End(span, err) }()
+
+	return u.next.Follow(ctx, userId, follow)
+}
+
+func (u *UserService) Unfollow(ctx context.Context, userId int, follow comicshelf.Follow) (err error) {
+	ctx, span := Start(ctx, "UserService.Unfollow", attribute.Int("user.id", userId), attribute.String("follow", follow.String()))
+	defer func() { End(span, err) }()
+
+	return u.next.Unfollow(ctx, userId, follow)
+}
+
+func (u *UserService) IssueStates(ctx context.Context, userId int) (_ map[int]comicshelf.IssueStates, err error) {
+	ctx, span := Start(ctx, "UserService.IssueStates", attribute.Int("user.id", userId))
+	defer func() { End(span, err) }()
+
+	return u.next.IssueStates(ctx, userId)
+}
+
+func (u *UserService) SetIssueState(ctx context.Context, userId, comicId int, state comicshelf.IssueState, at time.Time) (_ comicshelf.IssueStates, err error) {
+	ctx, span := Start(ctx, "UserService.SetIssueState", attribute.Int("user.id", userId), attribute.Int("comic.id", comicId), attribute.String("state", string(state)))
+	defer func() { End(span, err) }()
+
+	return u.next.SetIssueState(ctx, userId, comicId, state, at)
+}
+
+func (u *UserService) ClearIssueState(ctx context.Context, userId, comicId int, state comicshelf.IssueState) (_ comicshelf.IssueStates, err error) {
+	ctx, span := Start(ctx, "UserService.ClearIssueState", attribute.Int("user.id", userId), attribute.Int("comic.id", comicId), attribute.String("state", string(state)))
+	defer func() { End(span, err) }()
+
+	return u.next.ClearIssueState(ctx, userId, comicId, state)
+}
+
+func (u *UserService) NotificationsSeen(ctx context.Context, userId int) (_ time.Time, err error) {
+	ctx, span := Start(ctx, "UserService.NotificationsSeen", attribute.Int("user.id", userId))
+	defer func() { End(span, err) }()
+
+	return u.next.NotificationsSeen(ctx, userId)
+}
+
+func (u *UserService) SeeNotifications(ctx context.Context, userId int, at time.Time) (err error) {
+	ctx, span := Start(ctx, "UserService.SeeNotifications", attribute.Int("user.id", userId))
+	defer func() { End(span, err) }()
+
+	return u.next.SeeNotifications(ctx, userId, at)
+}
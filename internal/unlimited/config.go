@@ -0,0 +1,9 @@
+package unlimited
+
+type Config struct {
+	// MinSamples is how many issues a series, a format or every issue together needs before its delay is trusted.
+	// Short of it the next broader one is tried, ending with FallbackMonths.
+	MinSamples int `mapstructure:"min_samples"`
+	// FallbackMonths is the delay assumed before any issue has been seen reaching unlimited.
+	FallbackMonths int `mapstructure:"fallback_months"`
+}
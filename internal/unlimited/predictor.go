@@ -0,0 +1,216 @@
+// Package unlimited predicts when issues reach Marvel Unlimited from the delays seen on earlier issues.
+package unlimited
+
+import (
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/jakedegiovanni/comicshelf"
+)
+
+const (
+	BasisSeries   = "series"
+	BasisFormat   = "format"
+	BasisAll      = "all"
+	BasisFallback = "fallback"
+)
+
+const day = 24 * time.Hour
+
+type observation struct {
+	series int
+	format string
+	delay  int // days between print and unlimited
+}
+
+type stats struct {
+	median  int
+	spread  int // median absolute deviation in days
+	samples int
+}
+
+// Predictor records the print to unlimited delay of every issue it is shown that has already reached unlimited,
+// then predicts the date for those that haven't from the issues most like them.
+type Predictor struct {
+	cfg *Config
+	now func() time.Time
+
+	mu       sync.RWMutex
+	observed map[int]observation // keyed by comic so seeing an issue again replaces rather than double counts
+	series   map[int]stats
+	formats  map[string]stats
+	all      stats
+	dirty    bool
+}
+
+func New(cfg *Config) *Predictor {
+	return &Predictor{
+		cfg:      cfg,
+		now:      time.Now,
+		observed: make(map[int]observation),
+	}
+}
+
+// AddComics records the delay of each comic already on unlimited.
+func (p *Predictor) AddComics(comics ...comicshelf.Comic) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := p.now()
+	for _, comic := range comics {
+		if comic.OnSaleDate.IsZero() || comic.UnlimitedDate.IsZero() {
+			continue
+		}
+
+		// a scheduled date hasn't been seen yet and one before print is bad data
+		if comic.UnlimitedDate.After(now) || comic.UnlimitedDate.Before(comic.OnSaleDate) {
+			continue
+		}
+
+		p.observed[comic.Id] = observation{
+			series: comic.SeriesId,
+			format: comic.Format,
+			delay:  int(comic.UnlimitedDate.Sub(comic.OnSaleDate) / day),
+		}
+		p.dirty = true
+	}
+}
+
+func (p *Predictor) AddSeries(...comicshelf.Series) {}
+
+// Predict estimates the unlimited date of a comic, false when it is already known or there is no print date to go from.
+func (p *Predictor) Predict(comic comicshelf.Comic) (comicshelf.Prediction, bool) {
+	if !comic.UnlimitedDate.IsZero() || comic.OnSaleDate.IsZero() {
+		return comicshelf.Prediction{}, false
+	}
+
+	series, format, all := p.summaries(comic.SeriesId, comic.Format)
+
+	for _, candidate := range []struct {
+		basis string
+		stats stats
+	}{
+		{BasisSeries, series},
+		{BasisFormat, format},
+		{BasisAll, all},
+	} {
+		if candidate.stats.samples == 0 || candidate.stats.samples < p.cfg.MinSamples {
+			continue
+		}
+
+		return comicshelf.Prediction{
+			Date:       comic.OnSaleDate.AddDate(0, 0, candidate.stats.median),
+			Confidence: p.confidence(candidate.stats),
+			Samples:    candidate.stats.samples,
+			Basis:      candidate.basis,
+		}, true
+	}
+
+	return comicshelf.Prediction{
+		Date:  comic.OnSaleDate.AddDate(0, p.cfg.FallbackMonths, 0),
+		Basis: BasisFallback,
+	}, true
+}
+
+// summaries returns the stats a prediction is drawn from. Predictions share the read lock,
+// only the first one after new comics arrive takes the write lock to bring the stats up to date.
+func (p *Predictor) summaries(series int, format string) (stats, stats, stats) {
+	p.mu.RLock()
+	if p.dirty {
+		p.mu.RUnlock()
+		p.mu.Lock()
+		if p.dirty { // another prediction may have got there first
+			p.recompute()
+		}
+		p.mu.Unlock()
+		p.mu.RLock()
+	}
+	defer p.mu.RUnlock()
+
+	return p.series[series], p.formats[format], p.all
+}
+
+// Annotate returns a copy of the comics with predictions filled in where the unlimited date is unknown.
+func (p *Predictor) Annotate(comics []comicshelf.Comic) []comicshelf.Comic {
+	if comics == nil {
+		return nil
+	}
+
+	annotated := make([]comicshelf.Comic, len(comics))
+	for i, comic := range comics {
+		annotated[i] = p.annotate(comic)
+	}
+
+	return annotated
+}
+
+func (p *Predictor) annotate(comic comicshelf.Comic) comicshelf.Comic {
+	if prediction, ok := p.Predict(comic); ok {
+		comic.UnlimitedPrediction = &prediction
+	}
+
+	return comic
+}
+
+// confidence grows towards 1 with more samples and shrinks as their delays spread out, a week's spread halving it.
+func (p *Predictor) confidence(s stats) float64 {
+	k := float64(max(p.cfg.MinSamples, 1))
+	n := float64(s.samples)
+	c := n / (n + k) / (1 + float64(s.spread)/7)
+	return math.Round(c*100) / 100
+}
+
+func (p *Predictor) recompute() {
+	bySeries := make(map[int][]int)
+	byFormat := make(map[string][]int)
+	all := make([]int, 0, len(p.observed))
+
+	for _, o := range p.observed {
+		bySeries[o.series] = append(bySeries[o.series], o.delay)
+		byFormat[o.format] = append(byFormat[o.format], o.delay)
+		all = append(all, o.delay)
+	}
+
+	p.series = make(map[int]stats, len(bySeries))
+	for id, delays := range bySeries {
+		p.series[id] = summarise(delays)
+	}
+
+	p.formats = make(map[string]stats, len(byFormat))
+	for format, delays := range byFormat {
+		p.formats[format] = summarise(delays)
+	}
+
+	p.all = summarise(all)
+	p.dirty = false
+}
+
+func summarise(delays []int) stats {
+	if len(delays) == 0 {
+		return stats{}
+	}
+
+	m := median(delays)
+
+	deviations := make([]int, len(delays))
+	for i, d := range delays {
+		deviations[i] = abs(d - m)
+	}
+
+	return stats{median: m, spread: median(deviations), samples: len(delays)}
+}
+
+func median(values []int) int {
+	sorted := append([]int(nil), values...)
+	sort.Ints(sorted)
+	return sorted[len(sorted)/2]
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
@@ -0,0 +1,159 @@
+package unlimited
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jakedegiovanni/comicshelf"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var now = time.Date(2026, time.October, 19, 0, 0, 0, 0, time.UTC)
+
+func newTestPredictor(minSamples int) *Predictor {
+	p := New(&Config{MinSamples: minSamples, FallbackMonths: 3})
+	p.now = func() time.Time { return now }
+	return p
+}
+
+func released(id, series int, format string, onSale time.Time, delayDays int) comicshelf.Comic {
+	return comicshelf.Comic{
+		Id:            id,
+		SeriesId:      series,
+		Format:        format,
+		OnSaleDate:    onSale,
+		UnlimitedDate: onSale.AddDate(0, 0, delayDays),
+	}
+}
+
+func upcoming(series int, format string) comicshelf.Comic {
+	return comicshelf.Comic{Id: 100, SeriesId: series, Format: format, OnSaleDate: now}
+}
+
+func TestPredictPrefersSeries(t *testing.T) {
+	p := newTestPredictor(2)
+	onSale := now.AddDate(-1, 0, 0)
+	p.AddComics(
+		released(1, 10, "comic", onSale, 90),
+		released(2, 10, "comic", onSale, 92),
+		released(3, 20, "comic", onSale, 180),
+		released(4, 20, "comic", onSale, 180),
+		released(5, 20, "comic", onSale, 180),
+	)
+
+	prediction, ok := p.Predict(upcoming(10, "comic"))
+	require.True(t, ok)
+	assert.Equal(t, BasisSeries, prediction.Basis)
+	assert.Equal(t, 2, prediction.Samples)
+	assert.Equal(t, now.AddDate(0, 0, 92), prediction.Date)
+	assert.Greater(t, prediction.Confidence, 0.0)
+}
+
+func TestPredictFallsBackToFormatThenAll(t *testing.T) {
+	p := newTestPredictor(2)
+	onSale := now.AddDate(-1, 0, 0)
+	p.AddComics(
+		released(1, 10, "comic", onSale, 90),
+		released(2, 11, "comic", onSale, 90),
+		released(3, 12, "digest", onSale, 30),
+	)
+
+	prediction, ok := p.Predict(upcoming(99, "comic"))
+	require.True(t, ok)
+	assert.Equal(t, BasisFormat, prediction.Basis)
+	assert.Equal(t, now.AddDate(0, 0, 90), prediction.Date)
+
+	prediction, ok = p.Predict(upcoming(99, "omnibus"))
+	require.True(t, ok)
+	assert.Equal(t, BasisAll, prediction.Basis)
+	assert.Equal(t, 3, prediction.Samples)
+}
+
+func TestPredictFallback(t *testing.T) {
+	p := newTestPredictor(2)
+
+	prediction, ok := p.Predict(upcoming(10, "comic"))
+	require.True(t, ok)
+	assert.Equal(t, BasisFallback, prediction.Basis)
+	assert.Equal(t, now.AddDate(0, 3, 0), prediction.Date)
+	assert.Zero(t, prediction.Confidence)
+}
+
+func TestPredictOnlyUnknownDates(t *testing.T) {
+	p := newTestPredictor(1)
+
+	_, ok := p.Predict(released(1, 10, "comic", now, 90))
+	assert.False(t, ok)
+
+	_, ok = p.Predict(comicshelf.Comic{Id: 2})
+	assert.False(t, ok)
+}
+
+func TestAddComicsIgnoresUnseenDates(t *testing.T) {
+	p := newTestPredictor(1)
+	p.AddComics(
+		released(1, 10, "comic", now.AddDate(0, -1, 0), 90),  // scheduled, not yet on unlimited
+		released(2, 10, "comic", now.AddDate(-1, 0, 0), -10), // before print
+		released(3, 10, "comic", now.AddDate(-1, 0, 0), 60),
+		released(3, 10, "comic", now.AddDate(-1, 0, 0), 60), // seen twice
+	)
+
+	prediction, ok := p.Predict(upcoming(10, "comic"))
+	require.True(t, ok)
+	assert.Equal(t, 1, prediction.Samples)
+	assert.Equal(t, now.AddDate(0, 0, 60), prediction.Date)
+}
+
+func TestConfidence(t *testing.T) {
+	p := newTestPredictor(4)
+	onSale := now.AddDate(-1, 0, 0)
+
+	var consistent, spread []comicshelf.Comic
+	for i := 0; i < 8; i++ {
+		consistent = append(consistent, released(i, 10, "comic", onSale, 90))
+		spread = append(spread, released(100+i, 20, "comic", onSale, 60+i*10))
+	}
+	p.AddComics(consistent...)
+	p.AddComics(spread...)
+
+	tight, ok := p.Predict(upcoming(10, "comic"))
+	require.True(t, ok)
+	loose, ok := p.Predict(upcoming(20, "comic"))
+	require.True(t, ok)
+
+	assert.Greater(t, tight.Confidence, loose.Confidence)
+	assert.Less(t, tight.Confidence, 1.0)
+}
+
+func TestAnnotateCopies(t *testing.T) {
+	p := newTestPredictor(1)
+	comics := []comicshelf.Comic{upcoming(10, "comic")}
+
+	annotated := p.Annotate(comics)
+	require.NotNil(t, annotated[0].UnlimitedPrediction)
+	assert.Nil(t, comics[0].UnlimitedPrediction)
+}
+
+func TestPredictWhileAdding(t *testing.T) {
+	p := newTestPredictor(1)
+	onSale := now.AddDate(-1, 0, 0)
+	p.AddComics(released(1, 10, "comic", onSale, 90))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			p.AddComics(released(i+2, 10, "comic", onSale, 90))
+			_, ok := p.Predict(upcoming(10, "comic"))
+			assert.True(t, ok)
+		}(i)
+	}
+	wg.Wait()
+
+	prediction, ok := p.Predict(upcoming(10, "comic"))
+	require.True(t, ok)
+	assert.Equal(t, 9, prediction.Samples, "comics added between predictions are picked up")
+}
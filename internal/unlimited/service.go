@@ -0,0 +1,74 @@
+package unlimited
+
+import (
+	"context"
+	"time"
+
+	"github.com/jakedegiovanni/comicshelf"
+)
+
+var _ comicshelf.ComicService = (*Service)(nil)
+var _ comicshelf.SeriesService = (*Service)(nil)
+
+// Service fills in unlimited predictions on everything coming out of the wrapped services.
+type Service struct {
+	comics    comicshelf.ComicService
+	series    comicshelf.SeriesService
+	predictor *Predictor
+}
+
+func NewService(comics comicshelf.ComicService, series comicshelf.SeriesService, predictor *Predictor) *Service {
+	return &Service{
+		comics:    comics,
+		series:    series,
+		predictor: predictor,
+	}
+}
+
+func (s *Service) GetWeeklyComics(ctx context.Context, t time.Time) (comicshelf.Page[comicshelf.Comic], error) {
+	comics, err := s.comics.GetWeeklyComics(ctx, t)
+	if err != nil {
+		return comicshelf.Page[comicshelf.Comic]{}, err
+	}
+
+	comics.Results = s.predictor.Annotate(comics.Results)
+	return comics, nil
+}
+
+func (s *Service) GetReleasedComics(ctx context.Context, t time.Time) (comicshelf.Page[comicshelf.Comic], error) {
+	comics, err := s.comics.GetReleasedComics(ctx, t)
+	if err != nil {
+		return comicshelf.Page[comicshelf.Comic]{}, err
+	}
+
+	comics.Results = s.predictor.Annotate(comics.Results)
+	return comics, nil
+}
+
+func (s *Service) GetComic(ctx context.Context, id int) (comicshelf.Comic, error) {
+	comic, err := s.comics.GetComic(ctx, id)
+	if err != nil {
+		return comicshelf.Comic{}, err
+	}
+
+	return s.predictor.annotate(comic), nil
+}
+
+func (s *Service) GetComicsWithinSeries(ctx context.Context, id int) ([]comicshelf.Comic, error) {
+	comics, err := s.series.GetComicsWithinSeries(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.predictor.Annotate(comics), nil
+}
+
+func (s *Service) GetSeries(ctx context.Context, id int) (comicshelf.Series, error) {
+	series, err := s.series.GetSeries(ctx, id)
+	if err != nil {
+		return comicshelf.Series{}, err
+	}
+
+	series.Comics = s.predictor.Annotate(series.Comics)
+	return series, nil
+}
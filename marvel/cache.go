@@ -2,6 +2,8 @@ package marvel
 
 import "sync"
 
+// Cache holds responses by endpoint. A nil Cache is valid and caches nothing, which suits requests that are
+// unlikely to be repeated such as crawling.
 type Cache[T any] struct {
 	mu    *sync.RWMutex
 	cache map[string]T
@@ -15,6 +17,10 @@ func NewCache[T any]() *Cache[T] {
 }
 
 func (c *Cache[T]) Has(key string) bool {
+	if c == nil {
+		return false
+	}
+
 	c.mu.RLock()
 	defer c.mu.RUnlock()
 
@@ -23,6 +29,11 @@ func (c *Cache[T]) Has(key string) bool {
 }
 
 func (c *Cache[T]) Get(key string) (T, bool) {
+	if c == nil {
+		var zero T
+		return zero, false
+	}
+
 	c.mu.RLock()
 	defer c.mu.RUnlock()
 
@@ -31,6 +42,10 @@ func (c *Cache[T]) Get(key string) (T, bool) {
 }
 
 func (c *Cache[T]) Put(key string, val T) {
+	if c == nil {
+		return
+	}
+
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
@@ -38,6 +53,10 @@ func (c *Cache[T]) Put(key string, val T) {
 }
 
 func (c *Cache[T]) Delete(key string) {
+	if c == nil {
+		return
+	}
+
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
@@ -0,0 +1,46 @@
+package marvel
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jakedegiovanni/comicshelf"
+)
+
+var _ comicshelf.CharacterService = (*Client)(nil)
+
+type character struct {
+	baseResult
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+func (c *Client) GetCharacter(ctx context.Context, id int) (comicshelf.Character, error) {
+	character, err := getOne(ctx, c, fmt.Sprintf("/characters/%d", id), c.characterCache, "character", id)
+	if err != nil {
+		return comicshelf.Character{}, err
+	}
+
+	return transformCharacter(character), nil
+}
+
+func (c *Client) GetComicsWithCharacter(ctx context.Context, id int, from, to time.Time) ([]comicshelf.Comic, error) {
+	return c.comicsInRange(ctx, "characters", id, from, to)
+}
+
+func transformCharacter(character character) comicshelf.Character {
+	c := comicshelf.Character{
+		Id:          character.Id,
+		Name:        character.Name,
+		Description: character.Description,
+		Urls:        make([]comicshelf.Url, 0, len(character.Urls)),
+		Thumbnail:   fmt.Sprintf("%s/portrait_uncanny.%s", character.Thumbnail.Path, character.Thumbnail.Extension),
+	}
+
+	for _, uri := range character.Urls {
+		c.Urls = append(c.Urls, transformUrl(uri))
+	}
+
+	return c
+}
@@ -2,10 +2,12 @@ package marvel
 
 import (
 	"github.com/jakedegiovanni/comicshelf/internal/comicclient"
+	"github.com/jakedegiovanni/comicshelf/internal/release"
 )
 
 type Config struct {
-	Client        comicclient.Config `mapstructure:"client"`
-	DateLayout    string             `mapstructure:"date_layout"`
-	ReleaseOffset int                `mapstructure:"release_offset"`
+	Client          comicclient.Config `mapstructure:"client"`
+	DateLayout      string             `mapstructure:"date_layout"`
+	Release         release.Rules      `mapstructure:"release"`
+	UnlimitedOffset release.Offset     `mapstructure:"unlimited_offset"`
 }
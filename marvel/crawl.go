@@ -0,0 +1,50 @@
+package marvel
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jakedegiovanni/comicshelf"
+)
+
+// pageSize is the largest page marvel will return.
+const pageSize = 100
+
+// ComicsModifiedSince pages through comics changed since the given time, oldest change first. Responses are not
+// cached since a crawl is unlikely to repeat the same page.
+func (c *Client) ComicsModifiedSince(ctx context.Context, since time.Time, offset int) (comicshelf.Page[comicshelf.Comic], error) {
+	endpoint := fmt.Sprintf("/comics?format=comic&formatType=comic&noVariants=true&hasDigitalIssue=true&modifiedSince=%s&orderBy=modified&limit=%d&offset=%d", since.Format(c.cfg.DateLayout), pageSize, offset)
+	marvelComics, err := request[comic](ctx, endpoint, nil, c.client)
+	if err != nil {
+		return comicshelf.Page[comicshelf.Comic]{}, err
+	}
+
+	comics := transformPage[comic, comicshelf.Comic](marvelComics.Data)
+	for _, comic := range marvelComics.Data.Results {
+		com, err := transformComic(comic, marvelComics.AttributionText)
+		if err != nil {
+			return comicshelf.Page[comicshelf.Comic]{}, err
+		}
+		comics.Results = append(comics.Results, com)
+	}
+
+	return comics, nil
+}
+
+// SeriesModifiedSince pages through series changed since the given time, oldest change first. The comics within each
+// series are not fetched.
+func (c *Client) SeriesModifiedSince(ctx context.Context, since time.Time, offset int) (comicshelf.Page[comicshelf.Series], error) {
+	endpoint := fmt.Sprintf("/series?modifiedSince=%s&orderBy=modified&limit=%d&offset=%d", since.Format(c.cfg.DateLayout), pageSize, offset)
+	marvelSeries, err := request[series](ctx, endpoint, nil, c.client)
+	if err != nil {
+		return comicshelf.Page[comicshelf.Series]{}, err
+	}
+
+	results := transformPage[series, comicshelf.Series](marvelSeries.Data)
+	for _, s := range marvelSeries.Data.Results {
+		results.Results = append(results.Results, transformSeriesDetails(s))
+	}
+
+	return results, nil
+}
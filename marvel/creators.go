@@ -0,0 +1,44 @@
+package marvel
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jakedegiovanni/comicshelf"
+)
+
+var _ comicshelf.CreatorService = (*Client)(nil)
+
+type creator struct {
+	baseResult
+	FullName string `json:"fullName"`
+}
+
+func (c *Client) GetCreator(ctx context.Context, id int) (comicshelf.Creator, error) {
+	creator, err := getOne(ctx, c, fmt.Sprintf("/creators/%d", id), c.creatorCache, "creator", id)
+	if err != nil {
+		return comicshelf.Creator{}, err
+	}
+
+	return transformCreator(creator), nil
+}
+
+func (c *Client) GetComicsByCreator(ctx context.Context, id int, from, to time.Time) ([]comicshelf.Comic, error) {
+	return c.comicsInRange(ctx, "creators", id, from, to)
+}
+
+func transformCreator(creator creator) comicshelf.Creator {
+	c := comicshelf.Creator{
+		Id:        creator.Id,
+		FullName:  creator.FullName,
+		Urls:      make([]comicshelf.Url, 0, len(creator.Urls)),
+		Thumbnail: fmt.Sprintf("%s/portrait_uncanny.%s", creator.Thumbnail.Path, creator.Thumbnail.Extension),
+	}
+
+	for _, uri := range creator.Urls {
+		c.Urls = append(c.Urls, transformUrl(uri))
+	}
+
+	return c
+}
@@ -13,6 +13,10 @@ import (
 
 	"github.com/jakedegiovanni/comicshelf"
 	"github.com/jakedegiovanni/comicshelf/internal/comicclient"
+	"github.com/jakedegiovanni/comicshelf/internal/metrics"
+	"github.com/jakedegiovanni/comicshelf/internal/release"
+	"github.com/jakedegiovanni/comicshelf/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
 	"golang.org/x/sync/errgroup"
 )
 
@@ -34,6 +38,9 @@ func (m *marvelTime) UnmarshalJSON(b []byte) error {
 		m.Time = time.Time{}
 	}
 
+	// these are calendar dates stamped midnight in marvel's offset, kept as that day at midnight utc so no
+	// conversion to another zone can move them onto the day before
+	m.Time = time.Date(m.Time.Year(), m.Time.Month(), m.Time.Day(), 0, 0, 0, 0, time.UTC)
 	return nil
 }
 
@@ -57,6 +64,7 @@ type dataContainer[T any] struct {
 
 type item struct {
 	Name        string `json:"name"`
+	Role        string `json:"role"`
 	ResourceURI string `json:"resourceURI"`
 }
 
@@ -90,44 +98,90 @@ type baseResult struct {
 
 type series struct {
 	baseResult
-	Comics collection `json:"comics"`
+	Description string     `json:"description"`
+	StartYear   int        `json:"startYear"`
+	EndYear     int        `json:"endYear"`
+	Creators    collection `json:"creators"`
+	Comics      collection `json:"comics"`
+}
+
+type price struct {
+	Type  string  `json:"type"`
+	Price float64 `json:"price"`
 }
 
 type comic struct {
 	baseResult
-	Format      string `json:"format"`
-	IssueNumber int    `json:"issueNumber"`
-	Series      item   `json:"series"`
-	Dates       []date `json:"dates"`
+	Description        string     `json:"description"`
+	Format             string     `json:"format"`
+	IssueNumber        int        `json:"issueNumber"`
+	PageCount          int        `json:"pageCount"`
+	VariantDescription string     `json:"variantDescription"`
+	Variants           []item     `json:"variants"`
+	Prices             []price    `json:"prices"`
+	Creators           collection `json:"creators"`
+	Characters         collection `json:"characters"`
+	Series             item       `json:"series"`
+	Dates              []date     `json:"dates"`
 }
 
 type Client struct {
-	client      *http.Client
-	comicCache  *Cache[dataWrapper[comic]]
-	seriesCache *Cache[dataWrapper[series]]
-	cfg         *Config
+	client         *http.Client
+	comicCache     *Cache[dataWrapper[comic]]
+	seriesCache    *Cache[dataWrapper[series]]
+	creatorCache   *Cache[dataWrapper[creator]]
+	characterCache *Cache[dataWrapper[character]]
+	breaker        *comicclient.Breaker
+	cfg            *Config
 }
 
-func New(cfg *Config) *Client {
+// New creates a client, the given middleware sees every request once it is addressed and signed.
+func New(cfg *Config, middleware ...comicclient.Middleware) *Client {
+	breaker := comicclient.NewBreaker(&cfg.Client.Breaker)
+
 	return &Client{
 		client: comicclient.New(&cfg.Client, comicclient.MiddlewareChain(
+			breaker.Middleware(),
 			comicclient.AddBaseMiddleware(cfg.Client.BaseURL), // todo would prefer this to be managed by comicclient since it comes from its config
 			apiKeyMiddleware(),
+			comicclient.MiddlewareChain(middleware...),
 		)),
-		cfg:         cfg,
-		comicCache:  NewCache[dataWrapper[comic]](),
-		seriesCache: NewCache[dataWrapper[series]](),
+		breaker:        breaker,
+		cfg:            cfg,
+		comicCache:     NewCache[dataWrapper[comic]](),
+		seriesCache:    NewCache[dataWrapper[series]](),
+		creatorCache:   NewCache[dataWrapper[creator]](),
+		characterCache: NewCache[dataWrapper[character]](),
 	}
 }
 
-func (c *Client) GetWeeklyComics(ctx context.Context, t time.Time) (comicshelf.Page[comicshelf.Comic], error) {
-	if t.Weekday() == time.Sunday {
-		// sunday is when the cut off is however new comics show up on the monday, the offset set here makes expectations match reality
-		t = t.AddDate(0, 0, -1)
+// CheckCircuit reports whether requests to marvel are being refused after failing repeatedly.
+func (c *Client) CheckCircuit(ctx context.Context) error {
+	if c.breaker.Open() {
+		return comicclient.ErrCircuitOpen
 	}
 
-	first, last := c.weekRange(c.marvelUnlimitedDate(t))
-	endpoint := fmt.Sprintf("/comics?format=comic&formatType=comic&noVariants=true&dateRange=%s,%s&hasDigitalIssue=true&orderBy=issueNumber&limit=100", first.Format(c.cfg.DateLayout), last.Format(c.cfg.DateLayout))
+	return nil
+}
+
+func (c *Client) GetWeeklyComics(ctx context.Context, t time.Time) (comicshelf.Page[comicshelf.Comic], error) {
+	first, last := c.WeeklyRange(t)
+	return c.weeklyComics(ctx, first, last, "&hasDigitalIssue=true")
+}
+
+// WeeklyRange is the first and last on sale day GetWeeklyComics lists at t.
+// Those issues are the ones ComicsModifiedSince crawls, so a catalogue can answer for a week it has crawled.
+func (c *Client) WeeklyRange(t time.Time) (time.Time, time.Time) {
+	return c.cfg.Release.Week(t, c.cfg.UnlimitedOffset)
+}
+
+func (c *Client) GetReleasedComics(ctx context.Context, t time.Time) (comicshelf.Page[comicshelf.Comic], error) {
+	first, last := c.cfg.Release.Week(t, release.Offset{})
+	return c.weeklyComics(ctx, first, last, "")
+}
+
+func (c *Client) weeklyComics(ctx context.Context, first, last time.Time, filter string) (comicshelf.Page[comicshelf.Comic], error) {
+	endpoint := fmt.Sprintf("/comics?format=comic&formatType=comic&noVariants=true&dateRange=%s,%s%s&orderBy=issueNumber&limit=100", first.Format(c.cfg.DateLayout), last.Format(c.cfg.DateLayout), filter)
 
 	marvelComics, err := request[comic](ctx, endpoint, c.comicCache, c.client)
 	if err != nil {
@@ -155,15 +209,65 @@ func (c *Client) GetComic(ctx context.Context, id int) (comicshelf.Comic, error)
 	}
 
 	if marvelComic.Data.Count == 0 {
-		return comicshelf.Comic{}, fmt.Errorf("could not find comics for id: %d", id)
+		return comicshelf.Comic{}, fmt.Errorf("could not find comics for id: %d - %w", id, comicshelf.ErrNotFound)
 	}
 
 	return transformComic(marvelComic.Data.Results[0], marvelComic.AttributionText)
 }
 
+// GetComicsWithinSeries lists every issue in the series in issue order, print only ones included.
 func (c *Client) GetComicsWithinSeries(ctx context.Context, id int) ([]comicshelf.Comic, error) {
-	endpoint := fmt.Sprintf("/series/%d/comics?format=comic&formatType=comic&noVariants=true&hasDigitalIssue=true&orderBy=issueNumber&limit=100", id)
-	marvelComics, err := request[comic](ctx, endpoint, c.comicCache, c.client)
+	endpoint := fmt.Sprintf("/series/%d/comics?format=comic&formatType=comic&noVariants=true&orderBy=issueNumber", id)
+	return c.comicPages(ctx, endpoint)
+}
+
+// comicsInRange lists every comic on sale between from and to that the creator or character with id appears in.
+func (c *Client) comicsInRange(ctx context.Context, resource string, id int, from, to time.Time) ([]comicshelf.Comic, error) {
+	endpoint := fmt.Sprintf("/%s/%d/comics?format=comic&formatType=comic&noVariants=true&dateRange=%s,%s&orderBy=onsaleDate", resource, id, from.Format(c.cfg.DateLayout), to.Format(c.cfg.DateLayout))
+	return c.comicPages(ctx, endpoint)
+}
+
+// comicPages lists everything endpoint returns, paging through it when there is more than marvel returns at once.
+func (c *Client) comicPages(ctx context.Context, endpoint string) ([]comicshelf.Comic, error) {
+	var comics []comicshelf.Comic
+	for offset := 0; ; {
+		page, err := request[comic](ctx, fmt.Sprintf("%s&limit=%d&offset=%d", endpoint, pageSize, offset), c.comicCache, c.client)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, comic := range page.Data.Results {
+			com, err := transformComic(comic, page.AttributionText)
+			if err != nil {
+				return nil, err
+			}
+			comics = append(comics, com)
+		}
+
+		offset += page.Data.Count
+		if page.Data.Count == 0 || offset >= page.Data.Total {
+			return comics, nil
+		}
+	}
+}
+
+// getOne fetches the single result at endpoint, kind names it in the error when there is none.
+func getOne[T any](ctx context.Context, c *Client, endpoint string, cache *Cache[dataWrapper[T]], kind string, id int) (T, error) {
+	var zero T
+	resp, err := request[T](ctx, endpoint, cache, c.client)
+	if err != nil {
+		return zero, err
+	}
+
+	if resp.Data.Count == 0 || len(resp.Data.Results) == 0 {
+		return zero, fmt.Errorf("could not find %s with id: %d - %w", kind, id, comicshelf.ErrNotFound)
+	}
+
+	return resp.Data.Results[0], nil
+}
+
+func (c *Client) comicList(ctx context.Context, endpoint string, cache *Cache[dataWrapper[comic]]) ([]comicshelf.Comic, error) {
+	marvelComics, err := request[comic](ctx, endpoint, cache, c.client)
 	if err != nil {
 		return nil, err
 	}
@@ -172,7 +276,7 @@ func (c *Client) GetComicsWithinSeries(ctx context.Context, id int) ([]comicshel
 	for _, comic := range marvelComics.Data.Results {
 		com, err := transformComic(comic, marvelComics.AttributionText)
 		if err != nil {
-			return []comicshelf.Comic{}, nil
+			return nil, err
 		}
 		comics = append(comics, com)
 	}
@@ -180,32 +284,46 @@ func (c *Client) GetComicsWithinSeries(ctx context.Context, id int) ([]comicshel
 	return comics, nil
 }
 
+// GetSeries returns the series with its comics as GetComicsWithinSeries lists them.
 func (c *Client) GetSeries(ctx context.Context, id int) (comicshelf.Series, error) {
-	endpoint := fmt.Sprintf("/series/%d", id)
-	series, err := request[series](ctx, endpoint, c.seriesCache, c.client)
-	if err != nil {
-		return comicshelf.Series{}, err
-	}
+	var s comicshelf.Series
+	var comics []comicshelf.Comic
 
-	if series.Data.Count == 0 {
-		return comicshelf.Series{}, fmt.Errorf("could not find series with id: %d", id)
-	}
+	g, ctx := errgroup.WithContext(ctx)
+	g.Go(func() error {
+		endpoint := fmt.Sprintf("/series/%d", id)
+		series, err := request[series](ctx, endpoint, c.seriesCache, c.client)
+		if err != nil {
+			return err
+		}
 
-	return transformSeries(ctx, series.Data.Results[0], c.GetComic)
-}
+		if series.Data.Count == 0 {
+			return fmt.Errorf("could not find series with id: %d - %w", id, comicshelf.ErrNotFound)
+		}
 
-func (c *Client) weekRange(t time.Time) (time.Time, time.Time) {
-	for t.Weekday() != time.Sunday {
-		t = t.AddDate(0, 0, -1)
-	}
+		s = transformSeriesDetails(series.Data.Results[0])
+		return nil
+	})
+	g.Go(func() error {
+		ctx, span := tracing.Start(ctx, "marvel.transformSeries")
+		resp, err := c.GetComicsWithinSeries(ctx, id)
+		span.SetAttributes(attribute.Int("marvel.series.issues", len(resp)))
+		tracing.End(span, err)
+		if err != nil {
+			return err
+		}
 
-	t = t.AddDate(0, 0, -7)
+		comics = resp
+		return nil
+	})
 
-	return t, t.AddDate(0, 0, 6)
-}
+	err := g.Wait()
+	if err != nil {
+		return comicshelf.Series{}, err
+	}
 
-func (c *Client) marvelUnlimitedDate(t time.Time) time.Time {
-	return t.AddDate(0, c.cfg.ReleaseOffset, 0)
+	s.Comics = comics
+	return s, nil
 }
 
 func transformPage[C, P any](data dataContainer[C]) comicshelf.Page[P] {
@@ -218,57 +336,81 @@ func transformPage[C, P any](data dataContainer[C]) comicshelf.Page[P] {
 	}
 }
 
-func transformSeries(ctx context.Context, series series, getComic func(context.Context, int) (comicshelf.Comic, error)) (comicshelf.Series, error) {
+// transformSeriesDetails transforms everything but the comics within the series, which are listed separately.
+func transformSeriesDetails(series series) comicshelf.Series {
 	s := comicshelf.Series{
-		Id:        series.Id,
-		Title:     series.Title,
-		Urls:      make([]comicshelf.Url, 0, len(series.Urls)),
-		Thumbnail: fmt.Sprintf("%s/portrait_uncanny.%s", series.Thumbnail.Path, series.Thumbnail.Extension),
-		Comics:    make([]comicshelf.Comic, 0, len(series.Comics.Items)),
+		Id:          series.Id,
+		Title:       series.Title,
+		Description: series.Description,
+		StartYear:   series.StartYear,
+		EndYear:     series.EndYear,
+		Urls:        make([]comicshelf.Url, 0, len(series.Urls)),
+		Thumbnail:   fmt.Sprintf("%s/portrait_uncanny.%s", series.Thumbnail.Path, series.Thumbnail.Extension),
 	}
 
 	for _, uri := range series.Urls {
 		s.Urls = append(s.Urls, transformUrl(uri))
 	}
 
-	g := new(errgroup.Group)
-	g.SetLimit(len(series.Comics.Items))
-	for i, comic := range series.Comics.Items {
-		i, comic := i, comic // https://golang.org/doc/faq#closures_and_goroutines
-		g.Go(func() error {
-			id, err := extractId(comic.ResourceURI)
-			if err != nil {
-				return err
-			}
+	s.Creators = transformCredits(series.Creators)
+	return s
+}
 
-			c, err := getComic(ctx, id)
-			if err != nil {
-				return err
-			}
+// transformCredits and transformReferences skip any reference whose id cannot be read,
+// one bad link is not worth losing the comic or series it hangs off.
+func transformCredits(c collection) []comicshelf.Credit {
+	credits := make([]comicshelf.Credit, 0, len(c.Items))
+	for _, item := range c.Items {
+		id, err := extractId(item.ResourceURI)
+		if err != nil {
+			slog.Warn("skipping credit without an id", slog.String("uri", item.ResourceURI), slog.String("err", err.Error()))
+			continue
+		}
 
-			s.Comics[i] = c
-			return nil
+		credits = append(credits, comicshelf.Credit{
+			Reference: comicshelf.Reference{
+				Id:   id,
+				Name: item.Name,
+			},
+			Role: item.Role,
 		})
 	}
 
-	err := g.Wait()
-	if err != nil {
-		return comicshelf.Series{}, err
+	return credits
+}
+
+func transformReferences(items []item) []comicshelf.Reference {
+	refs := make([]comicshelf.Reference, 0, len(items))
+	for _, item := range items {
+		id, err := extractId(item.ResourceURI)
+		if err != nil {
+			slog.Warn("skipping reference without an id", slog.String("uri", item.ResourceURI), slog.String("err", err.Error()))
+			continue
+		}
+
+		refs = append(refs, comicshelf.Reference{
+			Id:   id,
+			Name: item.Name,
+		})
 	}
 
-	return s, nil
+	return refs
 }
 
 func transformComic(comic comic, attribution string) (comicshelf.Comic, error) {
 	c := comicshelf.Comic{
-		Id:              comic.Id,
-		Title:           comic.Title,
-		Urls:            make([]comicshelf.Url, 0, len(comic.Urls)),
-		Thumbnail:       fmt.Sprintf("%s/portrait_uncanny.%s", comic.Thumbnail.Path, comic.Thumbnail.Extension),
-		Format:          comic.Format,
-		IssuerNumber:    comic.IssueNumber,
-		Attribution:     attribution,
-		AttributionLink: "https://marvel.com",
+		Id:                 comic.Id,
+		Title:              comic.Title,
+		Description:        comic.Description,
+		Urls:               make([]comicshelf.Url, 0, len(comic.Urls)),
+		Thumbnail:          fmt.Sprintf("%s/portrait_uncanny.%s", comic.Thumbnail.Path, comic.Thumbnail.Extension),
+		Format:             comic.Format,
+		IssuerNumber:       comic.IssueNumber,
+		PageCount:          comic.PageCount,
+		Prices:             make([]comicshelf.Price, 0, len(comic.Prices)),
+		VariantDescription: comic.VariantDescription,
+		Attribution:        attribution,
+		AttributionLink:    "https://marvel.com",
 	}
 
 	seriesId, err := extractId(comic.Series.ResourceURI)
@@ -277,16 +419,28 @@ func transformComic(comic comic, attribution string) (comicshelf.Comic, error) {
 	}
 	c.SeriesId = seriesId
 
+	c.Creators = transformCredits(comic.Creators)
+	c.Characters = transformReferences(comic.Characters.Items)
+	c.Variants = transformReferences(comic.Variants)
+
+	for _, p := range comic.Prices {
+		c.Prices = append(c.Prices, comicshelf.Price{
+			Type:  p.Type,
+			Price: p.Price,
+		})
+	}
+
 	for _, uri := range comic.Urls {
 		c.Urls = append(c.Urls, transformUrl(uri))
 	}
 
 	for _, date := range comic.Dates {
-		if !strings.EqualFold(strings.ToLower(date.Type), "onsaledate") {
-			continue
+		switch strings.ToLower(date.Type) {
+		case "onsaledate":
+			c.OnSaleDate = date.Date.Time
+		case "unlimiteddate":
+			c.UnlimitedDate = date.Date.Time
 		}
-
-		c.OnSaleDate = date.Date.Time
 	}
 
 	return c, nil
@@ -311,45 +465,87 @@ func extractId(s string) (int, error) {
 		return id, nil
 	}
 
-	return 0, fmt.Errorf("could not extract a valid id from: %s", s)
+	return 0, fmt.Errorf("could not extract a valid id from: %s - %w", s, comicshelf.ErrUpstream)
 }
 
-func request[T any](ctx context.Context, endpoint string, cache *Cache[dataWrapper[T]], client *http.Client) (*dataWrapper[T], error) {
+func request[T any](ctx context.Context, endpoint string, cache *Cache[dataWrapper[T]], client *http.Client) (_ *dataWrapper[T], err error) {
+	ctx, span := tracing.Start(ctx, "marvel.request", attribute.String("marvel.endpoint", endpoint))
+	defer func() {
+		tracing.End(span, err)
+	}()
+
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
 	if err != nil {
 		return nil, fmt.Errorf("could not create request: %w", err)
 	}
 
+	// uncached requests such as searches and the crawl never look, so they are not counted as misses
+	var data dataWrapper[T]
+	var ok bool
+	if cache != nil {
+		_, lookup := tracing.Start(ctx, "marvel.cache.get")
+		data, ok = cache.Get(endpoint)
+		lookup.SetAttributes(attribute.Bool("cache.hit", ok))
+		lookup.End()
+	}
+
 	var resp *http.Response
-	if data, ok := cache.Get(endpoint); ok {
+	if ok {
+		metrics.CacheLookups.WithLabelValues(metrics.CacheHit).Inc()
 		req.Header.Set("If-None-Match", data.Etag)
 
 		resp, err = client.Do(req)
 		if err != nil {
-			return nil, fmt.Errorf("error whilst performing request: %w", err)
+			return nil, fmt.Errorf("error whilst performing request: %w - %w", err, comicshelf.ErrUpstream)
 		}
 
 		if resp.StatusCode == http.StatusNotModified {
-			slog.Debug("not modified, using cached response")
+			metrics.CacheLookups.WithLabelValues(metrics.CacheNotModified).Inc()
+			span.SetAttributes(attribute.Bool("cache.not_modified", true))
+			slog.DebugContext(ctx, "not modified, using cached response", slog.String("endpoint", endpoint))
 			return &data, nil
 		}
 	} else {
-		slog.Debug("item not present in cache")
+		if cache != nil {
+			metrics.CacheLookups.WithLabelValues(metrics.CacheMiss).Inc()
+			slog.DebugContext(ctx, "item not present in cache", slog.String("endpoint", endpoint))
+		}
 
 		resp, err = client.Do(req)
 		if err != nil {
-			return nil, fmt.Errorf("error whilst performing request: %w", err)
+			return nil, fmt.Errorf("error whilst performing request: %w - %w", err, comicshelf.ErrUpstream)
 		}
 	}
 
 	defer resp.Body.Close()
 
+	err = statusError(resp)
+	if err != nil {
+		return nil, err
+	}
+
 	var d dataWrapper[T]
 	err = json.NewDecoder(resp.Body).Decode(&d)
 	if err != nil {
-		return nil, fmt.Errorf("could not decode data wrapper: %w", err)
+		return nil, fmt.Errorf("could not decode data wrapper: %w - %w", err, comicshelf.ErrUpstream)
 	}
 
 	cache.Put(endpoint, d)
 	return &d, nil
 }
+
+// statusError maps a non successful marvel response onto the comicshelf sentinel errors.
+// Only a missing resource is down to what the caller asked for. Marvel answers 400 and 409 for parameters it
+// rejects, which are of our making rather than the user's, so like everything else they are upstream failures.
+func statusError(resp *http.Response) error {
+	if resp.StatusCode < http.StatusBadRequest {
+		return nil
+	}
+
+	sentinel := comicshelf.ErrUpstream
+	if resp.StatusCode == http.StatusNotFound {
+		sentinel = comicshelf.ErrNotFound
+	}
+
+	return fmt.Errorf("unexpected status from marvel: %d - %w", resp.StatusCode, sentinel)
+}
@@ -0,0 +1,290 @@
+package marvel
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/jakedegiovanni/comicshelf"
+	"github.com/jakedegiovanni/comicshelf/internal/comicclient"
+	"github.com/jakedegiovanni/comicshelf/internal/metrics"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestTransformComic(t *testing.T) {
+	tests := []struct {
+		name       string
+		comic      comic
+		err        bool
+		creators   []comicshelf.Credit
+		characters []comicshelf.Reference
+		variants   []comicshelf.Reference
+	}{
+		{
+			name: "references",
+			comic: comic{
+				Series:     item{ResourceURI: "http://gateway.marvel.com/v1/public/series/10"},
+				Creators:   collection{Items: []item{{Name: "Writer", Role: "writer", ResourceURI: "http://gateway.marvel.com/v1/public/creators/20"}}},
+				Characters: collection{Items: []item{{Name: "Hero", ResourceURI: "http://gateway.marvel.com/v1/public/characters/30"}}},
+				Variants:   []item{{Name: "Variant", ResourceURI: "http://gateway.marvel.com/v1/public/comics/40"}},
+			},
+			creators:   []comicshelf.Credit{{Reference: comicshelf.Reference{Id: 20, Name: "Writer"}, Role: "writer"}},
+			characters: []comicshelf.Reference{{Id: 30, Name: "Hero"}},
+			variants:   []comicshelf.Reference{{Id: 40, Name: "Variant"}},
+		},
+		{
+			name: "bad references are skipped",
+			comic: comic{
+				Series: item{ResourceURI: "http://gateway.marvel.com/v1/public/series/10"},
+				Creators: collection{Items: []item{
+					{Name: "Unknown", ResourceURI: "http://gateway.marvel.com/v1/public/creators/"},
+					{Name: "Writer", Role: "writer", ResourceURI: "http://gateway.marvel.com/v1/public/creators/20"},
+				}},
+				Characters: collection{Items: []item{{Name: "Unknown", ResourceURI: ""}}},
+				Variants:   []item{{Name: "Unknown", ResourceURI: "not a uri"}},
+			},
+			creators:   []comicshelf.Credit{{Reference: comicshelf.Reference{Id: 20, Name: "Writer"}, Role: "writer"}},
+			characters: []comicshelf.Reference{},
+			variants:   []comicshelf.Reference{},
+		},
+		{
+			name:  "bad series",
+			comic: comic{Series: item{ResourceURI: "http://gateway.marvel.com/v1/public/series/"}},
+			err:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := transformComic(tt.comic, "")
+			if tt.err {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, 10, c.SeriesId)
+			assert.Equal(t, tt.creators, c.Creators)
+			assert.Equal(t, tt.characters, c.Characters)
+			assert.Equal(t, tt.variants, c.Variants)
+		})
+	}
+}
+
+// newTestClient points a client at handler in place of marvel.
+func newTestClient(t *testing.T, handler http.HandlerFunc) *Client {
+	t.Helper()
+
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+
+	base, err := url.Parse(srv.URL)
+	require.NoError(t, err)
+
+	return New(&Config{
+		Client:     comicclient.Config{Timeout: time.Second, BaseURL: base},
+		DateLayout: "2006-01-02",
+	})
+}
+
+func TestGetComicsWithinSeriesPages(t *testing.T) {
+	const total = 2*pageSize + 1
+
+	var queries []url.Values
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		queries = append(queries, query)
+
+		offset, err := strconv.Atoi(query.Get("offset"))
+		require.NoError(t, err)
+
+		var d dataWrapper[comic]
+		d.Data.Offset = offset
+		d.Data.Total = total
+		for id := offset; id < min(offset+pageSize, total); id++ {
+			d.Data.Results = append(d.Data.Results, comic{
+				baseResult:  baseResult{Id: id},
+				IssueNumber: id + 1,
+				Series:      item{ResourceURI: "http://gateway.marvel.com/v1/public/series/10"},
+			})
+		}
+		d.Data.Count = len(d.Data.Results)
+
+		require.NoError(t, json.NewEncoder(w).Encode(d))
+	})
+
+	comics, err := c.GetComicsWithinSeries(context.Background(), 10)
+	require.NoError(t, err)
+	require.Len(t, comics, total)
+	assert.Equal(t, total, comics[total-1].IssuerNumber)
+
+	require.Len(t, queries, 3)
+	for i, query := range queries {
+		assert.Equal(t, strconv.Itoa(i*pageSize), query.Get("offset"))
+		assert.False(t, query.Has("hasDigitalIssue"), "print only issues are listed too")
+	}
+}
+
+func TestComicsInRangePages(t *testing.T) {
+	from := time.Date(2023, time.May, 2, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2023, time.November, 2, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		path string
+		list func(c *Client) ([]comicshelf.Comic, error)
+	}{
+		{
+			name: "creator",
+			path: "/creators/7/comics",
+			list: func(c *Client) ([]comicshelf.Comic, error) {
+				return c.GetComicsByCreator(context.Background(), 7, from, to)
+			},
+		},
+		{
+			name: "character",
+			path: "/characters/7/comics",
+			list: func(c *Client) ([]comicshelf.Comic, error) {
+				return c.GetComicsWithCharacter(context.Background(), 7, from, to)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			const total = pageSize + 5
+
+			requests := 0
+			c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+				requests++
+				assert.Equal(t, tt.path, r.URL.Path)
+				assert.Equal(t, "2023-05-02,2023-11-02", r.URL.Query().Get("dateRange"))
+
+				offset, err := strconv.Atoi(r.URL.Query().Get("offset"))
+				require.NoError(t, err)
+
+				var d dataWrapper[comic]
+				d.Data.Total = total
+				for id := offset; id < min(offset+pageSize, total); id++ {
+					d.Data.Results = append(d.Data.Results, comic{
+						baseResult: baseResult{Id: id},
+						Series:     item{ResourceURI: "http://gateway.marvel.com/v1/public/series/10"},
+					})
+				}
+				d.Data.Count = len(d.Data.Results)
+
+				require.NoError(t, json.NewEncoder(w).Encode(d))
+			})
+
+			comics, err := tt.list(c)
+			require.NoError(t, err)
+			assert.Len(t, comics, total, "issues past the first page are kept")
+			assert.Equal(t, 2, requests)
+		})
+	}
+}
+
+func TestGetSeriesTraced(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/series/10" {
+			var d dataWrapper[series]
+			d.Data.Count, d.Data.Total = 1, 1
+			d.Data.Results = []series{{baseResult: baseResult{Id: 10, Title: "Series"}}}
+			require.NoError(t, json.NewEncoder(w).Encode(d))
+			return
+		}
+
+		var d dataWrapper[comic]
+		d.Data.Count, d.Data.Total = 2, 2
+		d.Data.Results = []comic{
+			{baseResult: baseResult{Id: 1}, IssueNumber: 1, Series: item{ResourceURI: "http://gateway.marvel.com/v1/public/series/10"}},
+			{baseResult: baseResult{Id: 2}, IssueNumber: 2, Series: item{ResourceURI: "http://gateway.marvel.com/v1/public/series/10"}},
+		}
+		require.NoError(t, json.NewEncoder(w).Encode(d))
+	})
+
+	s, err := c.GetSeries(context.Background(), 10)
+	require.NoError(t, err)
+	assert.Equal(t, "Series", s.Title)
+	assert.Len(t, s.Comics, 2)
+
+	var found bool
+	for _, span := range recorder.Ended() {
+		if span.Name() != "marvel.transformSeries" {
+			continue
+		}
+
+		found = true
+		assert.Contains(t, span.Attributes(), attribute.Int("marvel.series.issues", 2))
+	}
+	assert.True(t, found, "listing the series' comics is traced")
+}
+
+func TestRequestCountsCacheMisses(t *testing.T) {
+	tests := []struct {
+		name   string
+		cache  *Cache[dataWrapper[comic]]
+		misses float64
+	}{
+		{name: "cached", cache: NewCache[dataWrapper[comic]](), misses: 1},
+		{name: "uncached", cache: nil, misses: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+				require.NoError(t, json.NewEncoder(w).Encode(dataWrapper[comic]{}))
+			})
+
+			misses := metrics.CacheLookups.WithLabelValues(metrics.CacheMiss)
+			before := testutil.ToFloat64(misses)
+
+			_, err := request[comic](context.Background(), "/comics", tt.cache, c.client)
+			require.NoError(t, err)
+			assert.Equal(t, tt.misses, testutil.ToFloat64(misses)-before)
+		})
+	}
+}
+
+func TestStatusError(t *testing.T) {
+	tests := []struct {
+		status int
+		want   error
+	}{
+		{status: http.StatusOK},
+		{status: http.StatusNotModified},
+		{status: http.StatusBadRequest, want: comicshelf.ErrUpstream},
+		{status: http.StatusUnauthorized, want: comicshelf.ErrUpstream},
+		{status: http.StatusNotFound, want: comicshelf.ErrNotFound},
+		{status: http.StatusConflict, want: comicshelf.ErrUpstream},
+		{status: http.StatusTooManyRequests, want: comicshelf.ErrUpstream},
+		{status: http.StatusInternalServerError, want: comicshelf.ErrUpstream},
+	}
+
+	for _, tt := range tests {
+		t.Run(strconv.Itoa(tt.status), func(t *testing.T) {
+			err := statusError(&http.Response{StatusCode: tt.status})
+			if tt.want == nil {
+				assert.NoError(t, err)
+				return
+			}
+
+			assert.ErrorIs(t, err, tt.want)
+			assert.NotErrorIs(t, err, comicshelf.ErrInvalidInput)
+		})
+	}
+}
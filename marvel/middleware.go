@@ -1,11 +1,14 @@
 package marvel
 
 import (
+	"context"
 	"crypto/md5"
 	_ "embed"
+	"errors"
 	"fmt"
 	"log/slog"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/jakedegiovanni/comicshelf/internal/comicclient"
@@ -19,6 +22,15 @@ var pub string
 //go:embed priv.txt
 var priv string
 
+// CheckCredentials reports whether api keys were built in.
+func CheckCredentials(ctx context.Context) error {
+	if strings.TrimSpace(pub) == "" || strings.TrimSpace(priv) == "" {
+		return errors.New("marvel api keys are missing")
+	}
+
+	return nil
+}
+
 func apiKeyMiddleware() comicclient.Middleware {
 	return func(next http.RoundTripper) http.RoundTripper {
 		return comicclient.MiddlewareFn(func(req *http.Request) (*http.Response, error) {
@@ -30,7 +42,7 @@ func apiKeyMiddleware() comicclient.Middleware {
 			query.Add("apikey", pub)
 			req.URL.RawQuery = query.Encode()
 
-			slog.Debug("api key middleware")
+			slog.DebugContext(req.Context(), "api key middleware")
 			return next.RoundTrip(req)
 		})
 	}
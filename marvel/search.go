@@ -0,0 +1,32 @@
+package marvel
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+
+	"github.com/jakedegiovanni/comicshelf"
+)
+
+// SearchComics finds comics whose title starts with prefix. Search responses are not cached, every keystroke is
+// a new prefix that is unlikely to be asked for again and a Cache keeps everything put in it for good.
+func (c *Client) SearchComics(ctx context.Context, prefix string) ([]comicshelf.Comic, error) {
+	endpoint := fmt.Sprintf("/comics?titleStartsWith=%s&format=comic&formatType=comic&noVariants=true&orderBy=-onsaleDate&limit=20", url.QueryEscape(prefix))
+	return c.comicList(ctx, endpoint, nil)
+}
+
+// SearchSeries finds series whose title starts with prefix, uncached for the same reason as SearchComics.
+func (c *Client) SearchSeries(ctx context.Context, prefix string) ([]comicshelf.Series, error) {
+	endpoint := fmt.Sprintf("/series?titleStartsWith=%s&orderBy=title&limit=20", url.QueryEscape(prefix))
+	marvelSeries, err := request[series](ctx, endpoint, nil, c.client)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]comicshelf.Series, 0, marvelSeries.Data.Count)
+	for _, s := range marvelSeries.Data.Results {
+		results = append(results, transformSeriesDetails(s))
+	}
+
+	return results, nil
+}
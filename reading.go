@@ -0,0 +1,29 @@
+package comicshelf
+
+import "time"
+
+type IssueState string
+
+const (
+	StateRead          IssueState = "read"
+	StateOwnedDigital  IssueState = "owned_digital"
+	StateOwnedPhysical IssueState = "owned_physical"
+	StateWishlist      IssueState = "wishlist"
+)
+
+func (s IssueState) Valid() bool {
+	switch s {
+	case StateRead, StateOwnedDigital, StateOwnedPhysical, StateWishlist:
+		return true
+	default:
+		return false
+	}
+}
+
+// IssueStates records when each state was applied to an issue, a state which is absent does not apply.
+type IssueStates map[IssueState]time.Time
+
+func (s IssueStates) Has(state IssueState) bool {
+	_, ok := s[state]
+	return ok
+}
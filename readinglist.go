@@ -0,0 +1,36 @@
+package comicshelf
+
+import (
+	"context"
+	"time"
+)
+
+// ReadingList is an ordered list of comics owned by a user, such as the reading order of a crossover event.
+type ReadingList struct {
+	Id          int       `json:"id"`
+	OwnerId     int       `json:"owner_id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	Comics      []int     `json:"comics"`
+	Shared      bool      `json:"shared"`
+	Created     time.Time `json:"created"`
+	Updated     time.Time `json:"updated"`
+}
+
+type ReadingListService interface {
+	ReadingLists(ctx context.Context, userId int) ([]ReadingList, error)
+	// ReadingList returns a list owned by the user or shared by its owner.
+	ReadingList(ctx context.Context, userId, listId int) (ReadingList, error)
+	CreateReadingList(ctx context.Context, userId int, list ReadingList) (ReadingList, error)
+	// UpdateReadingList replaces the name, description and sharing of a list the user owns. Its comics are left as
+	// they are, they only change through the methods below so concurrent edits cannot undo each other.
+	UpdateReadingList(ctx context.Context, userId int, list ReadingList) (ReadingList, error)
+	DeleteReadingList(ctx context.Context, userId, listId int) error
+	// AddToReadingList appends a comic to a list the user owns, a comic already on the list stays where it is.
+	AddToReadingList(ctx context.Context, userId, listId, comicId int) (ReadingList, error)
+	// RemoveFromReadingList takes a comic off a list the user owns.
+	RemoveFromReadingList(ctx context.Context, userId, listId, comicId int) (ReadingList, error)
+	// ReorderReadingList puts the comics of a list the user owns in the given order, which must hold exactly the
+	// comics on the list - ErrConflict otherwise.
+	ReorderReadingList(ctx context.Context, userId, listId int, order []int) (ReadingList, error)
+}
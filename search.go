@@ -0,0 +1,22 @@
+package comicshelf
+
+import "context"
+
+type SearchKind string
+
+const (
+	SearchComic  SearchKind = "comic"
+	SearchSeries SearchKind = "series"
+)
+
+type SearchResult struct {
+	Kind      SearchKind `json:"kind"`
+	Id        int        `json:"id"`
+	Title     string     `json:"title"`
+	Thumbnail string     `json:"thumbnail"`
+	Score     float64    `json:"score"`
+}
+
+type SearchService interface {
+	Search(ctx context.Context, query string) ([]SearchResult, error)
+}
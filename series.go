@@ -3,14 +3,20 @@ package comicshelf
 import "context"
 
 type Series struct {
-	Comics    []Comic `json:"comics"`
-	Id        int     `json:"id"`
-	Title     string  `json:"title"`
-	Urls      []Url   `json:"urls"`
-	Thumbnail string  `json:"thumbnail"`
+	// Comics is every issue in the series in issue order, variants left out and print only issues included.
+	Comics      []Comic  `json:"comics"`
+	Id          int      `json:"id"`
+	Title       string   `json:"title"`
+	Description string   `json:"description"`
+	StartYear   int      `json:"start_year"`
+	EndYear     int      `json:"end_year"`
+	Creators    []Credit `json:"creators"`
+	Urls        []Url    `json:"urls"`
+	Thumbnail   string   `json:"thumbnail"`
 }
 
 type SeriesService interface {
+	// GetComicsWithinSeries lists the same issues a series' Comics holds.
 	GetComicsWithinSeries(ctx context.Context, id int) ([]Comic, error)
 	GetSeries(ctx context.Context, id int) (Series, error)
 }
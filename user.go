@@ -1,15 +1,108 @@
 package comicshelf
 
-import "context"
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type FollowKind string
+
+const (
+	FollowSeries    FollowKind = "series"
+	FollowCreator   FollowKind = "creator"
+	FollowCharacter FollowKind = "character"
+)
+
+func (k FollowKind) Valid() bool {
+	switch k {
+	case FollowSeries, FollowCreator, FollowCharacter:
+		return true
+	default:
+		return false
+	}
+}
+
+// Follow identifies something a user follows. It encodes as "kind:id" so that it can be used as a json map key.
+type Follow struct {
+	Kind FollowKind `json:"kind"`
+	Id   int        `json:"id"`
+}
+
+func (f Follow) String() string {
+	return fmt.Sprintf("%s:%d", f.Kind, f.Id)
+}
+
+func (f Follow) MarshalText() ([]byte, error) {
+	return []byte(f.String()), nil
+}
+
+func (f *Follow) UnmarshalText(b []byte) error {
+	kind, id, ok := strings.Cut(string(b), ":")
+	if !ok {
+		return fmt.Errorf("follow is not in kind:id form: %s - %w", b, ErrInvalidInput)
+	}
+
+	if !FollowKind(kind).Valid() {
+		return fmt.Errorf("unknown follow kind: %s - %w", kind, ErrInvalidInput)
+	}
+
+	i, err := strconv.Atoi(id)
+	if err != nil {
+		return fmt.Errorf("follow id is not a valid number: %s - %w", id, ErrInvalidInput)
+	}
+
+	f.Kind = FollowKind(kind)
+	f.Id = i
+	return nil
+}
 
 type User struct {
-	Id        int      `json:"id"`
-	Following Set[int] `json:"following"`
+	Id        int                 `json:"id"`
+	Following Set[Follow]         `json:"following"`
+	Issues    map[int]IssueStates `json:"issues"`
+	// NotificationsSeen is when the user last looked at what was released for their follows, zero if they never have.
+	NotificationsSeen time.Time `json:"notifications_seen"`
 }
 
 type UserService interface {
-	Following(ctx context.Context, userId, seriesId int) (bool, error)
-	Followed(ctx context.Context, userId int) (Set[int], error)
-	Follow(ctx context.Context, userId, seriesId int) error
-	Unfollow(ctx context.Context, userId, seriesId int) error
+	Following(ctx context.Context, userId int, follow Follow) (bool, error)
+	Followed(ctx context.Context, userId int) (Set[Follow], error)
+	Follow(ctx context.Context, userId int, follow Follow) error
+	Unfollow(ctx context.Context, userId int, follow Follow) error
+	// IssueStates returns the tracked states of every issue the user has tracked, keyed by comic id.
+	IssueStates(ctx context.Context, userId int) (map[int]IssueStates, error)
+	// SetIssueState applies a state to an issue as of the given time, returning the issue's states afterwards.
+	// A state already set keeps the earlier of the two times.
+	SetIssueState(ctx context.Context, userId, comicId int, state IssueState, at time.Time) (IssueStates, error)
+	// ClearIssueState removes a state from an issue, returning the issue's states afterwards.
+	ClearIssueState(ctx context.Context, userId, comicId int, state IssueState) (IssueStates, error)
+	// NotificationsSeen returns when the user last looked at their notifications, zero if they never have.
+	NotificationsSeen(ctx context.Context, userId int) (time.Time, error)
+	// SeeNotifications records that the user has looked at their notifications as of the given time.
+	SeeNotifications(ctx context.Context, userId int, at time.Time) error
+}
+
+// Pulled reports whether a comic belongs on the pull list described by follows, either through its series or
+// through one of its creators or characters.
+func Pulled(follows Set[Follow], c Comic) bool {
+	if follows.Has(Follow{Kind: FollowSeries, Id: c.SeriesId}) {
+		return true
+	}
+
+	for _, creator := range c.Creators {
+		if follows.Has(Follow{Kind: FollowCreator, Id: creator.Id}) {
+			return true
+		}
+	}
+
+	for _, character := range c.Characters {
+		if follows.Has(Follow{Kind: FollowCharacter, Id: character.Id}) {
+			return true
+		}
+	}
+
+	return false
 }